@@ -3,6 +3,10 @@ module hello
 go 1.15
 
 require (
-	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200728190242-9b3ae92d8664 // indirect
-	github.com/snlansky/coral v0.0.0-20201026071308-1a9f6462b748
+	github.com/golang/protobuf v1.3.3
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200728190242-9b3ae92d8664
+	github.com/hyperledger/fabric-protos-go v0.0.0-20191121202242-f5500d5e3e85
+	github.com/tmthrgd/go-hex v0.0.0-20190904060850-447a3041c3bc
+	golang.org/x/crypto v0.0.0-20191108234033-bd318be0434a
+	google.golang.org/grpc v1.29.1
 )
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"github.com/snlansky/coral/pkg/contract"
-	"github.com/snlansky/coral/pkg/contract/impl"
+	"hello/pkg/contract"
+	"hello/pkg/contract/impl"
 )
 
 type MyService struct {
@@ -15,5 +15,5 @@ func (s *MyService) SayHello(stub contract.IContractStub, name string) string {
 func main() {
 	cc := impl.NewFabricChaincode()
 	cc.Register(&MyService{})
-	cc.Start()
+	cc.StartServer()
 }
@@ -0,0 +1,256 @@
+package cctest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/impl"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const defaultChannelID = "cctest-channel"
+
+// Harness runs a chaincode through shim.Chaincode like a peer does, so that
+// tests cover argument parsing, identities, JSON marshalling and error
+// mapping of the chaincode, e.g.
+//
+//	h := cctest.NewHarness("mycc", cc)
+//	resp := h.Invoke(cctest.MustNewIdentity("Org1MSP", "bob", nil), "MyService.SayHello", "bob")
+//
+// Every invocation is a transaction of its own on the memory chain of the
+// impl package. Its writes are committed when its status is below
+// shim.ERRORTHRESHOLD and discarded otherwise.
+type Harness struct {
+	name        string
+	channel     string
+	cc          shim.Chaincode
+	chain       *impl.MemoryFactoryChain
+	collections map[string][]string // collection -> member MSP IDs
+	clock       func() time.Time
+}
+
+// NewHarness deploys cc under name on a channel of its own.
+func NewHarness(name string, cc *impl.FabricChaincode) *Harness {
+	h := &Harness{
+		name:        name,
+		channel:     defaultChannelID,
+		cc:          cc,
+		chain:       impl.NewMemoryFactoryChain(impl.WithTxSimulation()),
+		collections: map[string][]string{},
+		clock:       time.Now,
+	}
+	h.chain.Deploy(name, cc, h.channel)
+	return h
+}
+
+// Deploy deploys another chaincode which can be called with InvokeChaincode.
+func (h *Harness) Deploy(name string, cc *impl.FabricChaincode) {
+	h.chain.Deploy(name, cc, h.channel)
+}
+
+// DefineCollection declares a private data collection readable by the
+// member organizations.
+func (h *Harness) DefineCollection(name string, memberMSPIDs ...string) {
+	h.collections[name] = memberMSPIDs
+	h.chain.DefineCollection(name, memberMSPIDs...)
+}
+
+// SetClock sets the clock which stamps the timestamp of every transaction.
+func (h *Harness) SetClock(clock func() time.Time) {
+	h.clock = clock
+}
+
+func (h *Harness) newStub(opts ...impl.StubOption) contract.IContractStub {
+	opts = append([]impl.StubOption{
+		impl.WithChannel(h.channel),
+		impl.WithChaincode(h.name),
+		impl.WithTimestamp(h.clock()),
+	}, opts...)
+	return h.chain.NewStub("", opts...)
+}
+
+// Init calls Init of the chaincode with args.
+func (h *Harness) Init(id *Identity, args ...[]byte) pb.Response {
+	stub, err := h.newMockStub(id, args, nil)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return h.commit(stub, h.cc.Init(stub))
+}
+
+// Invoke calls method with params marshalled to a JSON array, the arguments
+// expected by impl.FabricChaincode.
+func (h *Harness) Invoke(id *Identity, method string, params ...interface{}) pb.Response {
+	return h.InvokeWithTransient(id, nil, method, params...)
+}
+
+// InvokeWithTransient is like Invoke with a transient map.
+func (h *Harness) InvokeWithTransient(id *Identity, transient map[string][]byte, method string, params ...interface{}) pb.Response {
+	if params == nil {
+		params = []interface{}{}
+	}
+	buf, err := json.Marshal(params)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return h.InvokeArgs(id, [][]byte{[]byte(method), buf}, transient)
+}
+
+// InvokeArgs calls Invoke of the chaincode with raw arguments.
+func (h *Harness) InvokeArgs(id *Identity, args [][]byte, transient map[string][]byte) pb.Response {
+	stub, err := h.newMockStub(id, args, transient)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return h.commit(stub, h.cc.Invoke(stub))
+}
+
+func (h *Harness) newMockStub(id *Identity, args [][]byte, transient map[string][]byte) (*MockStub, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	creator := id.Creator()
+	txHash := sha256.Sum256(append(append([]byte{}, nonce...), creator...))
+	txID := hex.EncodeToString(txHash[:])
+	now := h.clock()
+	ts, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, err := h.newSignedProposal(id, txID, nonce, args, transient, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch := make([]byte, 8)
+	binary.LittleEndian.PutUint64(epoch, 0)
+	binding := sha256.Sum256(append(append(append([]byte{}, nonce...), creator...), epoch...))
+
+	stub := h.chain.NewStub("",
+		impl.WithChannel(h.channel),
+		impl.WithChaincode(h.name),
+		impl.WithCreator(creator),
+		impl.WithArgs(args...),
+		impl.WithTransient(transient),
+		impl.WithTxID(txID),
+		impl.WithTimestamp(now),
+	)
+	return &MockStub{
+		stub:           stub,
+		chaincode:      h.name,
+		creator:        creator,
+		timestamp:      ts,
+		signedProposal: proposal,
+		binding:        binding[:],
+	}, nil
+}
+
+func (h *Harness) newSignedProposal(id *Identity, txID string, nonce []byte, args [][]byte,
+	transient map[string][]byte, ts *timestamp.Timestamp) (*pb.SignedProposal, error) {
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: h.channel,
+		TxId:      txID,
+		Timestamp: ts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: id.Creator(), Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+	header, err := proto.Marshal(&common.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader})
+	if err != nil {
+		return nil, err
+	}
+	input, err := proto.Marshal(&pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: h.name},
+		Input:       &pb.ChaincodeInput{Args: args},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(&pb.ChaincodeProposalPayload{Input: input, TransientMap: transient})
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := proto.Marshal(&pb.Proposal{Header: header, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := id.Sign(proposal)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedProposal{ProposalBytes: proposal, Signature: signature}, nil
+}
+
+// commit commits the transaction of a successful invocation and discards
+// the one of a failed invocation.
+func (h *Harness) commit(stub *MockStub, resp pb.Response) pb.Response {
+	if resp.Status >= shim.ERRORTHRESHOLD {
+		h.chain.Rollback(stub.stub)
+		return resp
+	}
+	if err := h.chain.Commit(stub.stub); err != nil {
+		return shim.Error(err.Error())
+	}
+	return resp
+}
+
+// GetState returns the committed value of key of the chaincode.
+func (h *Harness) GetState(key string) []byte {
+	v, _ := h.newStub().GetState(key)
+	return v
+}
+
+// PutState seeds the world state of the chaincode in a transaction of its
+// own.
+func (h *Harness) PutState(key string, value []byte) {
+	stub := h.newStub()
+	if err := stub.PutState(key, value); err != nil {
+		panic(err)
+	}
+	if err := h.chain.Commit(stub); err != nil {
+		panic(err)
+	}
+}
+
+// GetPrivateData returns the committed value of key in the collection.
+func (h *Harness) GetPrivateData(collection, key string) []byte {
+	members := h.collections[collection]
+	if len(members) == 0 {
+		return nil
+	}
+	v, _ := h.newStub(impl.WithMSPID(members[0])).GetPrivateData(collection, key)
+	return v
+}
+
+// Events returns the events of the committed transactions in order.
+func (h *Harness) Events() []*pb.ChaincodeEvent {
+	var events []*pb.ChaincodeEvent
+	for _, e := range h.chain.Events(impl.EventFilter{}) {
+		events = append(events, &pb.ChaincodeEvent{
+			ChaincodeId: e.ChaincodeID,
+			TxId:        e.TxID,
+			EventName:   e.EventName,
+			Payload:     e.Payload,
+		})
+	}
+	return events
+}
@@ -0,0 +1,230 @@
+package cctest_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/cctest"
+	"hello/pkg/contract/impl"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+type Wallet struct{}
+
+func (w *Wallet) Deposit(ctx contract.Context, amount int) (int, error) {
+	caller, err := ctx.Caller()
+	if err != nil {
+		return 0, err
+	}
+	key := caller.Address.String()
+	v, err := ctx.Stub().GetState(key)
+	if err != nil {
+		return 0, err
+	}
+	balance, _ := strconv.Atoi(string(v))
+	balance += amount
+	if err := ctx.Stub().PutState(key, []byte(strconv.Itoa(balance))); err != nil {
+		return 0, err
+	}
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+	return balance, ctx.AddEvent("wallet", "deposited", amount)
+}
+
+func (w *Wallet) Whoami(ctx contract.Context) (string, error) {
+	caller, err := ctx.Caller()
+	if err != nil {
+		return "", err
+	}
+	role, _ := caller.GetAttributeValue("role")
+	return caller.MSPID + " " + role, nil
+}
+
+func (w *Wallet) Time(ctx contract.Context) (string, error) {
+	t, err := ctx.TxTime()
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+func (w *Wallet) Hide(stub contract.IContractStub, key string) (bool, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return false, err
+	}
+	return true, stub.PutPrivateData("secrets", key, transient["secret"])
+}
+
+// Proposal reports whether the chaincode was handed a signed proposal and
+// its binding.
+func (w *Wallet) Proposal(stub contract.IContractStub) (bool, error) {
+	origin, ok := stub.GetOriginStub().(shim.ChaincodeStubInterface)
+	if !ok {
+		return false, errors.New("not a shim stub")
+	}
+	proposal, err := origin.GetSignedProposal()
+	if err != nil {
+		return false, err
+	}
+	binding, err := origin.GetBinding()
+	if err != nil {
+		return false, err
+	}
+	return len(proposal.ProposalBytes) > 0 && len(proposal.Signature) > 0 && len(binding) > 0, nil
+}
+
+type Bank struct{}
+
+// Deposit deposits amount into the wallet of the caller.
+func (b *Bank) Deposit(stub contract.IContractStub, amount int) (string, error) {
+	args := [][]byte{[]byte("Wallet.Deposit"), []byte("[" + strconv.Itoa(amount) + "]")}
+	payload, err := stub.InvokeContract("wallet", args, "")
+	return string(payload), err
+}
+
+func newHarness() *cctest.Harness {
+	cc := impl.NewFabricChaincode()
+	cc.Register(&Wallet{})
+	return cctest.NewHarness("wallet", cc)
+}
+
+func TestHarnessInvoke(t *testing.T) {
+	h := newHarness()
+	bob := cctest.MustNewIdentity("Org1MSP", "bob", nil)
+
+	resp := h.Invoke(bob, "Wallet.Deposit", 5)
+	if resp.Status != 200 || string(resp.Payload) != "5" {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+	if got := string(h.GetState(bob.Address.String())); got != "5" {
+		t.Fatalf("balance = %q", got)
+	}
+
+	resp = h.Invoke(bob, "Wallet.Deposit", -1)
+	if resp.Status != 500 || resp.Message != "amount must be positive" {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	if got := string(h.GetState(bob.Address.String())); got != "5" {
+		t.Fatalf("balance after a failed deposit = %q", got)
+	}
+
+	events := h.Events()
+	if len(events) != 1 || events[0].EventName != contract.EventEnvelopeName || events[0].ChaincodeId != "wallet" {
+		t.Fatalf("events = %v", events)
+	}
+	decoded, err := contract.DecodeEventEnvelope(events[0].Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].Name != "wallet.deposited" {
+		t.Fatalf("envelope = %s", events[0].Payload)
+	}
+}
+
+func TestHarnessInvokeArgs(t *testing.T) {
+	h := newHarness()
+	bob := cctest.MustNewIdentity("Org1MSP", "bob", nil)
+
+	resp := h.InvokeArgs(bob, [][]byte{[]byte("Wallet.Deposit"), []byte("[5")}, nil)
+	if resp.Status != 500 || resp.Message != contract.ERR_JSON_UNMARSHAL {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	resp = h.InvokeArgs(bob, [][]byte{[]byte("Wallet.Deposit"), []byte(`{"amount":2}`)}, nil)
+	if resp.Status == 200 {
+		t.Fatalf("named params without names: got %d %s", resp.Status, resp.Payload)
+	}
+}
+
+func TestHarnessIdentity(t *testing.T) {
+	h := newHarness()
+	admin := cctest.MustNewIdentity("Org2MSP", "alice", map[string]string{"role": "admin"})
+
+	resp := h.Invoke(admin, "Wallet.Whoami")
+	if resp.Status != 200 || string(resp.Payload) != `"Org2MSP admin"` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestHarnessClock(t *testing.T) {
+	h := newHarness()
+	h.SetClock(impl.FixedClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	resp := h.Invoke(cctest.MustNewIdentity("Org1MSP", "bob", nil), "Wallet.Time")
+	if resp.Status != 200 || string(resp.Payload) != `"2020-01-02T03:04:05Z"` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestHarnessTransientAndPrivateData(t *testing.T) {
+	h := newHarness()
+	h.DefineCollection("secrets", "Org1MSP")
+	bob := cctest.MustNewIdentity("Org1MSP", "bob", nil)
+
+	transient := map[string][]byte{"secret": []byte("s3cret")}
+	resp := h.InvokeWithTransient(bob, transient, "Wallet.Hide", "k")
+	if resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	if got := string(h.GetPrivateData("secrets", "k")); got != "s3cret" {
+		t.Fatalf("private data = %q", got)
+	}
+	if got := h.GetPrivateData("missing", "k"); got != nil {
+		t.Fatalf("undefined collection = %q", got)
+	}
+}
+
+func TestHarnessPutState(t *testing.T) {
+	h := newHarness()
+	bob := cctest.MustNewIdentity("Org1MSP", "bob", nil)
+	h.PutState(bob.Address.String(), []byte("10"))
+
+	resp := h.Invoke(bob, "Wallet.Deposit", 1)
+	if resp.Status != 200 || string(resp.Payload) != "11" {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestHarnessInit(t *testing.T) {
+	h := newHarness()
+	resp := h.Init(cctest.MustNewIdentity("Org1MSP", "bob", nil))
+	if resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+}
+
+func TestHarnessSignedProposal(t *testing.T) {
+	h := newHarness()
+	resp := h.Invoke(cctest.MustNewIdentity("Org1MSP", "bob", nil), "Wallet.Proposal")
+	if resp.Status != 200 || string(resp.Payload) != "true" {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestHarnessInvokeChaincode(t *testing.T) {
+	bank := impl.NewFabricChaincode()
+	bank.Register(&Bank{})
+	h := cctest.NewHarness("bank", bank)
+	wallet := impl.NewFabricChaincode()
+	wallet.Register(&Wallet{})
+	h.Deploy("wallet", wallet)
+	bob := cctest.MustNewIdentity("Org1MSP", "bob", nil)
+
+	resp := h.Invoke(bob, "Bank.Deposit", 3)
+	if resp.Status != 200 || string(resp.Payload) != `"3"` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+	resp = h.Invoke(bob, "Bank.Deposit", -1)
+	if resp.Status != 500 || resp.Message != "amount must be positive" {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	resp = h.Invoke(bob, "Bank.Deposit", 1)
+	if resp.Status != 200 || string(resp.Payload) != `"4"` {
+		t.Fatalf("failed deposit kept: got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
@@ -0,0 +1,98 @@
+package cctest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"hello/pkg/contract/identity"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// Identity is a client of the harness with a self-signed ECDSA certificate.
+type Identity struct {
+	MSPID   string
+	Cert    *x509.Certificate
+	Key     *ecdsa.PrivateKey
+	Address identity.Address
+	certPEM []byte
+}
+
+// NewIdentity generates an identity of the organization mspID. Its
+// certificate carries attrs like one enrolled with Fabric CA.
+func NewIdentity(mspID, commonName string, attrs map[string]string) (*Identity, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{mspID}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if len(attrs) > 0 {
+		ext, err := identity.AttributesExtension(attrs)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.ExtraExtensions = []pkix.Extension{ext}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := identity.PublicKeyIntoAddress(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{
+		MSPID:   mspID,
+		Cert:    cert,
+		Key:     key,
+		Address: addr,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// MustNewIdentity is like NewIdentity but panics on error.
+func MustNewIdentity(mspID, commonName string, attrs map[string]string) *Identity {
+	id, err := NewIdentity(mspID, commonName, attrs)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Creator returns the serialized identity as returned by GetCreator.
+func (id *Identity) Creator() []byte {
+	buf, _ := proto.Marshal(&msp.SerializedIdentity{Mspid: id.MSPID, IdBytes: id.certPEM})
+	return buf
+}
+
+// Sign signs the SHA-256 digest of msg like a Fabric client.
+func (id *Identity) Sign(msg []byte) ([]byte, error) {
+	digest := sha256.Sum256(msg)
+	return ecdsa.SignASN1(rand.Reader, id.Key, digest[:])
+}
@@ -0,0 +1,65 @@
+package cctest
+
+import (
+	"hello/pkg/contract"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// stateIterator hands the results of a memory stub query to the chaincode
+// as shim query results.
+type stateIterator struct {
+	it        contract.IStateIterator
+	namespace string
+}
+
+var _ shim.StateQueryIteratorInterface = (*stateIterator)(nil)
+
+func (it *stateIterator) HasNext() bool {
+	return it.it.HasNext()
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return &queryresult.KV{Namespace: it.namespace, Key: kv.Key, Value: kv.Value}, nil
+}
+
+func (it *stateIterator) Close() error {
+	return it.it.Close()
+}
+
+type historyIterator struct {
+	it contract.IHistoryIterator
+}
+
+var _ shim.HistoryQueryIteratorInterface = (*historyIterator)(nil)
+
+func (it *historyIterator) HasNext() bool {
+	return it.it.HasNext()
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	mod, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := ptypes.TimestampProto(mod.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return &queryresult.KeyModification{
+		TxId:      mod.TxID,
+		Value:     mod.Value,
+		Timestamp: ts,
+		IsDelete:  mod.IsDelete,
+	}, nil
+}
+
+func (it *historyIterator) Close() error {
+	return it.it.Close()
+}
@@ -0,0 +1,263 @@
+package cctest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"hello/pkg/contract"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const compositeKeyNamespace = "\x00"
+
+// MockStub is the shim.ChaincodeStubInterface of one transaction of a
+// Harness. It adds the signed proposal of the transaction to a stub of the
+// memory chain, which keeps the world state: reads see the committed state
+// and writes are committed by the harness when the chaincode succeeds.
+type MockStub struct {
+	stub           contract.IContractStub // memory stub of the transaction
+	chaincode      string
+	creator        []byte
+	timestamp      *timestamp.Timestamp
+	signedProposal *pb.SignedProposal
+	binding        []byte
+}
+
+var _ shim.ChaincodeStubInterface = (*MockStub)(nil)
+
+func (s *MockStub) GetArgs() [][]byte {
+	return s.stub.GetArgs()
+}
+
+func (s *MockStub) GetStringArgs() []string {
+	args := s.GetArgs()
+	strargs := make([]string, 0, len(args))
+	for _, arg := range args {
+		strargs = append(strargs, string(arg))
+	}
+	return strargs
+}
+
+func (s *MockStub) GetFunctionAndParameters() (string, []string) {
+	allargs := s.GetStringArgs()
+	if len(allargs) == 0 {
+		return "", []string{}
+	}
+	return allargs[0], allargs[1:]
+}
+
+func (s *MockStub) GetArgsSlice() ([]byte, error) {
+	return bytes.Join(s.GetArgs(), nil), nil
+}
+
+func (s *MockStub) GetTxID() string {
+	return s.stub.GetTxID()
+}
+
+func (s *MockStub) GetChannelID() string {
+	return s.stub.GetChannelID()
+}
+
+// InvokeChaincode runs a chaincode deployed on the harness in the same
+// transaction. Its event is discarded like on a peer.
+func (s *MockStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	payload, err := s.stub.InvokeContract(chaincodeName, args, channel)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(payload)
+}
+
+func (s *MockStub) GetState(key string) ([]byte, error) {
+	return s.stub.GetState(key)
+}
+
+func (s *MockStub) PutState(key string, value []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return s.stub.PutState(key, value)
+}
+
+func (s *MockStub) DelState(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	_, err := s.stub.DelState(key)
+	return err
+}
+
+func (s *MockStub) SetStateValidationParameter(key string, ep []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return s.stub.SetStateValidationParameter(key, ep)
+}
+
+func (s *MockStub) GetStateValidationParameter(key string) ([]byte, error) {
+	return s.stub.GetStateValidationParameter(key)
+}
+
+func (s *MockStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return s.states(s.stub.GetStateByRange(startKey, endKey))
+}
+
+func (s *MockStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	return s.page(s.stub.GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark))
+}
+
+func (s *MockStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return s.states(s.stub.GetStateByPartialCompositeKey(objectType, keys))
+}
+
+func (s *MockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+	pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	return s.page(s.stub.GetStateByPartialCompositeKeyWithPagination(objectType, keys, pageSize, bookmark))
+}
+
+func (s *MockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return contract.CreateCompositeKey(objectType, attributes)
+}
+
+func (s *MockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return contract.SplitCompositeKey(compositeKey)
+}
+
+// GetQueryResult evaluates a CouchDB Mango query like the memory chain.
+func (s *MockStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	return s.states(s.stub.GetQueryResult(query))
+}
+
+func (s *MockStub) GetQueryResultWithPagination(query string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	return s.page(s.stub.GetQueryResultWithPagination(query, pageSize, bookmark))
+}
+
+// GetHistoryForKey returns the committed modifications of key, newest first.
+func (s *MockStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	it, err := s.stub.GetHistoryForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &historyIterator{it: it}, nil
+}
+
+func (s *MockStub) GetPrivateData(collection, key string) ([]byte, error) {
+	return s.stub.GetPrivateData(collection, key)
+}
+
+func (s *MockStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	return s.stub.GetPrivateDataHash(collection, key)
+}
+
+func (s *MockStub) PutPrivateData(collection string, key string, value []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return s.stub.PutPrivateData(collection, key, value)
+}
+
+func (s *MockStub) DelPrivateData(collection, key string) error {
+	return s.stub.DelPrivateData(collection, key)
+}
+
+func (s *MockStub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return s.stub.SetPrivateDataValidationParameter(collection, key, ep)
+}
+
+func (s *MockStub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return s.stub.GetPrivateDataValidationParameter(collection, key)
+}
+
+func (s *MockStub) GetPrivateDataByRange(collection, startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return s.states(s.stub.GetPrivateDataByRange(collection, startKey, endKey))
+}
+
+func (s *MockStub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return s.states(s.stub.GetPrivateDataByPartialCompositeKey(collection, objectType, keys))
+}
+
+// GetPrivateDataQueryResult is not supported, the memory chain has no rich
+// queries over private data.
+func (s *MockStub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errors.New("rich queries on private data are not supported by the mock stub")
+}
+
+func (s *MockStub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+func (s *MockStub) GetTransient() (map[string][]byte, error) {
+	return s.stub.GetTransient()
+}
+
+func (s *MockStub) GetBinding() ([]byte, error) {
+	return s.binding, nil
+}
+
+func (s *MockStub) GetDecorations() map[string][]byte {
+	return map[string][]byte{}
+}
+
+func (s *MockStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return s.signedProposal, nil
+}
+
+func (s *MockStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return s.timestamp, nil
+}
+
+func (s *MockStub) SetEvent(name string, payload []byte) error {
+	return s.stub.SetEvent(name, payload)
+}
+
+func (s *MockStub) states(it contract.IStateIterator, err error) (shim.StateQueryIteratorInterface, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{it: it, namespace: s.chaincode}, nil
+}
+
+func (s *MockStub) page(it contract.IStateIterator, meta *contract.QueryMetadata, err error) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	return &stateIterator{it: it, namespace: s.chaincode}, &pb.QueryResponseMetadata{
+		FetchedRecordsCount: meta.FetchedRecordsCount,
+		Bookmark:            meta.Bookmark,
+	}, nil
+}
+
+func validateKey(key string) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("key %x is not a valid utf8 string", key)
+	}
+	return nil
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if len(key) > 0 && key[:1] == compositeKeyNamespace {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
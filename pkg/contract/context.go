@@ -0,0 +1,94 @@
+package contract
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"hello/pkg/contract/identity"
+)
+
+// Context is the per-call context a service method can take instead of
+// IContractStub as its first argument, e.g.
+//
+//	func (s *MyService) SayHello(ctx contract.Context, name string) (string, error)
+type Context interface {
+	// Stub returns the stub of the transaction.
+	Stub() IContractStub
+	// Caller returns the identity of the client which submitted the transaction.
+	Caller() (*identity.ClientIdentity, error)
+	TxID() string
+	TxTime() (time.Time, error)
+	// Method returns the invoked "Service.Method".
+	Method() string
+	// Logger returns a logger which prefixes the tx id and method.
+	Logger() *log.Logger
+	// AddEvent adds an event like the function AddEvent.
+	AddEvent(appName, eventName string, v interface{}) error
+	// Cache returns a map which lives as long as the call.
+	Cache() map[string]interface{}
+}
+
+type callContext struct {
+	stub   IContractStub
+	method string
+	caller *identity.ClientIdentity
+	logger *log.Logger
+	cache  map[string]interface{}
+}
+
+// NewContext returns the context of a call of method. The caller, logger
+// and cache are created when first used.
+func NewContext(stub IContractStub, method string) Context {
+	return &callContext{stub: stub, method: method}
+}
+
+func (c *callContext) Stub() IContractStub {
+	return c.stub
+}
+
+func (c *callContext) Caller() (*identity.ClientIdentity, error) {
+	if c.caller == nil {
+		caller, err := c.stub.GetClientIdentity()
+		if err != nil {
+			return nil, err
+		}
+		c.caller = caller
+	}
+	return c.caller, nil
+}
+
+func (c *callContext) TxID() string {
+	return c.stub.GetTxID()
+}
+
+func (c *callContext) TxTime() (time.Time, error) {
+	return c.stub.GetTxTimestamp()
+}
+
+func (c *callContext) Method() string {
+	return c.method
+}
+
+func (c *callContext) Logger() *log.Logger {
+	if c.logger == nil {
+		txID := c.stub.GetTxID()
+		if len(txID) > 8 {
+			txID = txID[:8]
+		}
+		c.logger = log.New(os.Stderr, fmt.Sprintf("[%s %s] ", txID, c.method), log.LstdFlags)
+	}
+	return c.logger
+}
+
+func (c *callContext) AddEvent(appName, eventName string, v interface{}) error {
+	return AddEvent(c.stub, appName, eventName, v)
+}
+
+func (c *callContext) Cache() map[string]interface{} {
+	if c.cache == nil {
+		c.cache = map[string]interface{}{}
+	}
+	return c.cache
+}
@@ -0,0 +1,79 @@
+package contract_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/impl"
+)
+
+func TestContext(t *testing.T) {
+	chain := impl.NewMemoryFactoryChain()
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	stub := chain.NewStub(testAddr, impl.WithTxID("0123456789abcdef"), impl.WithTimestamp(now),
+		impl.WithMSPID("Org1MSP"), impl.WithAttributes(map[string]string{"role": "admin"}))
+	ctx := contract.NewContext(stub, "Shop.Buy")
+
+	if ctx.Stub() != stub || ctx.Method() != "Shop.Buy" || ctx.TxID() != "0123456789abcdef" {
+		t.Fatalf("stub, method or tx id of the context is wrong")
+	}
+	if tt, err := ctx.TxTime(); err != nil || !tt.Equal(now) {
+		t.Fatalf("tx time = %v, %v", tt, err)
+	}
+
+	caller, err := ctx.Caller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caller.MSPID != "Org1MSP" || !strings.EqualFold(caller.Address.String(), testAddr) {
+		t.Fatalf("caller = %+v", caller)
+	}
+	if role, _ := caller.GetAttributeValue("role"); role != "admin" {
+		t.Fatalf("role = %q", role)
+	}
+	if again, _ := ctx.Caller(); again != caller {
+		t.Fatal("caller is resolved again")
+	}
+
+	ctx.Cache()["k"] = 1
+	if ctx.Cache()["k"] != 1 {
+		t.Fatal("cache does not live as long as the context")
+	}
+	if other := contract.NewContext(stub, "Shop.Buy"); len(other.Cache()) != 0 {
+		t.Fatal("cache is shared between contexts")
+	}
+
+	var out bytes.Buffer
+	ctx.Logger().SetOutput(&out)
+	ctx.Logger().Print("hello")
+	if !strings.HasPrefix(out.String(), "[01234567 Shop.Buy] ") {
+		t.Fatalf("log = %q", out.String())
+	}
+}
+
+func TestContextAddEvent(t *testing.T) {
+	chain := impl.NewMemoryFactoryChain(impl.WithTxSimulation())
+	stub := chain.NewStub(testAddr)
+	ctx := contract.NewContext(stub, "Shop.Buy")
+	if err := ctx.AddEvent("shop", "bought", map[string]int{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	events := chain.Events(impl.EventFilter{})
+	if len(events) != 1 || events[0].EventName != contract.EventEnvelopeName {
+		t.Fatalf("events = %v", events)
+	}
+	decoded, err := contract.DecodeEventEnvelope(events[0].Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].Name != "shop.bought" || string(decoded[0].Payload) != `{"n":1}` {
+		t.Fatalf("envelope = %s", events[0].Payload)
+	}
+}
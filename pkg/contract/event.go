@@ -0,0 +1,78 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// EventEnvelopeName is the name of the chaincode event which carries the
+// events added during a transaction.
+const EventEnvelopeName = "coral.events"
+
+// Event is one event in an event envelope.
+type Event struct {
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EventEnvelope is the payload of the EventEnvelopeName event.
+type EventEnvelope struct {
+	Events []*Event `json:"events"`
+}
+
+// CreateEvent sets the event of the transaction, named appName.eventName,
+// with the payload as is. Like SetEvent it replaces the event set before,
+// unless events are added with AddEvent, see EventEnvelopeName.
+func CreateEvent(stub IContractStub, appName, eventName string, payload []byte) error {
+	return stub.SetEvent(MakeEventName(appName, eventName), payload)
+}
+
+// AddEvent adds an event with v marshalled to JSON to the transaction. Every
+// added event is delivered, in an envelope emitted when the invocation
+// succeeds. The event set with CreateEvent or SetEvent, if any, is the first
+// event of the envelope.
+func AddEvent(stub IContractStub, appName, eventName string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return stub.AddEvent(MakeEventName(appName, eventName), payload)
+}
+
+func MakeEventName(appName, eventName string) string {
+	return strings.Join([]string{appName, eventName}, ".")
+}
+
+// DecodeEventEnvelope unpacks the payload of an EventEnvelopeName event.
+func DecodeEventEnvelope(payload []byte) ([]*Event, error) {
+	envelope := &EventEnvelope{}
+	if err := json.Unmarshal(payload, envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Events, nil
+}
+
+// WrapEvent makes an envelope event of an event with any payload. A payload
+// which is not JSON is carried as a base64 string.
+func WrapEvent(name string, payload []byte) (*Event, error) {
+	if !json.Valid(payload) {
+		buf, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = buf
+	}
+	return NewEvent(name, payload)
+}
+
+// NewEvent checks an event added by IContractStub.AddEvent.
+func NewEvent(name string, payload []byte) (*Event, error) {
+	if name == "" {
+		return nil, errors.New("event name can not be empty string")
+	}
+	if !json.Valid(payload) {
+		return nil, errors.New("event payload is not valid json")
+	}
+	return &Event{Name: name, Payload: payload}, nil
+}
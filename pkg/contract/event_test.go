@@ -0,0 +1,44 @@
+package contract_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"hello/pkg/contract"
+)
+
+func TestWrapEvent(t *testing.T) {
+	e, err := contract.WrapEvent("app.created", []byte(`{"id":1}`))
+	if err != nil || string(e.Payload) != `{"id":1}` {
+		t.Fatalf("JSON payload: got %s, %v", e.Payload, err)
+	}
+
+	e, err = contract.WrapEvent("app.created", []byte("raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw []byte
+	if err := json.Unmarshal(e.Payload, &raw); err != nil || string(raw) != "raw" {
+		t.Fatalf("raw payload: got %s, %v", e.Payload, err)
+	}
+
+	if _, err := contract.WrapEvent("", nil); err == nil {
+		t.Fatal("event without name accepted")
+	}
+	if _, err := contract.NewEvent("app.created", []byte("raw")); err == nil {
+		t.Fatal("event with a payload which is not JSON accepted")
+	}
+}
+
+func TestDecodeEventEnvelope(t *testing.T) {
+	events, err := contract.DecodeEventEnvelope([]byte(`{"events":[{"name":"a.b","payload":1},{"name":"a.c","payload":"x"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Name != "a.b" || string(events[1].Payload) != `"x"` {
+		t.Fatalf("got %+v %+v", events[0], events[1])
+	}
+	if _, err := contract.DecodeEventEnvelope([]byte("nope")); err == nil {
+		t.Fatal("garbage decoded")
+	}
+}
@@ -0,0 +1,136 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// attrOID is the certificate extension in which Fabric CA stores attributes.
+var attrOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// ClientIdentity is the identity of the client that submitted a transaction.
+type ClientIdentity struct {
+	MSPID   string
+	Cert    *x509.Certificate
+	Address Address
+	Subject string
+	Issuer  string
+	Attrs   map[string]string
+}
+
+// NewClientIdentity parses the creator of a proposal, a serialized
+// msp.SerializedIdentity holding a PEM encoded x509 certificate.
+func NewClientIdentity(creatorByte []byte) (*ClientIdentity, error) {
+	sid := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creatorByte, sid); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal serialized identity: %s", err)
+	}
+	cert, err := parseCertificate(sid.IdBytes)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := PublicKeyIntoAddress(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := certAttributes(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientIdentity{
+		MSPID:   sid.Mspid,
+		Cert:    cert,
+		Address: addr,
+		Subject: cert.Subject.String(),
+		Issuer:  cert.Issuer.String(),
+		Attrs:   attrs,
+	}, nil
+}
+
+// GetAttributeValue returns the value of the Fabric CA attribute, e.g.
+// "hf.EnrollmentID".
+func (c *ClientIdentity) GetAttributeValue(name string) (string, bool) {
+	value, ok := c.Attrs[name]
+	return value, ok
+}
+
+// AssertAttributeValue returns an error unless the attribute has the value.
+func (c *ClientIdentity) AssertAttributeValue(name, value string) error {
+	got, ok := c.GetAttributeValue(name)
+	if !ok {
+		return fmt.Errorf("attribute '%s' was not found", name)
+	}
+	if got != value {
+		return fmt.Errorf("attribute '%s' equals '%s', not '%s'", name, got, value)
+	}
+	return nil
+}
+
+func parseCertificate(certText []byte) (*x509.Certificate, error) {
+	bl, _ := pem.Decode(certText)
+	if bl == nil {
+		return nil, errors.New("could not decode the PEM structure")
+	}
+	cert, err := x509.ParseCertificate(bl.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %s", err)
+	}
+	return cert, nil
+}
+
+// creatorCertificate extracts the certificate of a serialized identity and
+// falls back to searching the PEM block for creators of other formats.
+func creatorCertificate(creatorByte []byte) (*x509.Certificate, error) {
+	sid := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creatorByte, sid); err == nil && len(sid.IdBytes) > 0 {
+		return parseCertificate(sid.IdBytes)
+	}
+
+	certStart := bytes.Index(creatorByte, []byte("-----BEGIN"))
+	if certStart == -1 {
+		return nil, errors.New("no creator certificate found")
+	}
+	return parseCertificate(creatorByte[certStart:])
+}
+
+// AttributesExtension returns the certificate extension in which Fabric CA
+// stores attrs, e.g. for certificates of test identities.
+func AttributesExtension(attrs map[string]string) (pkix.Extension, error) {
+	value, err := json.Marshal(certAttrs{Attrs: attrs})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: attrOID, Value: value}, nil
+}
+
+// certAttrs is the value of the attribute extension.
+type certAttrs struct {
+	Attrs map[string]string `json:"attrs"`
+}
+
+func certAttributes(cert *x509.Certificate) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(attrOID) {
+			continue
+		}
+		var v certAttrs
+		if err := json.Unmarshal(ext.Value, &v); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal certificate attributes: %s", err)
+		}
+		for k, value := range v.Attrs {
+			attrs[k] = value
+		}
+	}
+	return attrs, nil
+}
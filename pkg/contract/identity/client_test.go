@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+func testCreator(t *testing.T, mspID string, attrs map[string]string) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "bob"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if attrs != nil {
+		ext, err := AttributesExtension(attrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.ExtraExtensions = []pkix.Extension{ext}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return creator, key
+}
+
+func TestNewClientIdentity(t *testing.T) {
+	creator, key := testCreator(t, "Org1MSP", map[string]string{"hf.EnrollmentID": "bob", "role": "admin"})
+	ci, err := NewClientIdentity(creator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.MSPID != "Org1MSP" {
+		t.Errorf("got MSP ID %q", ci.MSPID)
+	}
+	if !strings.Contains(ci.Subject, "CN=bob") || ci.Subject != ci.Issuer {
+		t.Errorf("got subject %q, issuer %q", ci.Subject, ci.Issuer)
+	}
+	addr, err := PublicKeyIntoAddress(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.Address != addr {
+		t.Errorf("got address %s, want %s", ci.Address, addr)
+	}
+	if got, err := IntoAddress(creator); err != nil || got != addr {
+		t.Errorf("IntoAddress: got %s, %v", got, err)
+	}
+
+	if err := ci.AssertAttributeValue("role", "admin"); err != nil {
+		t.Error(err)
+	}
+	if err := ci.AssertAttributeValue("role", "user"); err == nil {
+		t.Error("wrong attribute value asserted")
+	}
+	if err := ci.AssertAttributeValue("missing", ""); err == nil {
+		t.Error("missing attribute asserted")
+	}
+	if v, ok := ci.GetAttributeValue("hf.EnrollmentID"); !ok || v != "bob" {
+		t.Errorf("got enrollment id %q, %v", v, ok)
+	}
+}
+
+func TestNewClientIdentityWithoutAttributes(t *testing.T) {
+	creator, _ := testCreator(t, "Org2MSP", nil)
+	ci, err := NewClientIdentity(creator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ci.Attrs) != 0 {
+		t.Fatalf("got attrs %v", ci.Attrs)
+	}
+}
+
+func TestNewClientIdentityInvalid(t *testing.T) {
+	bad, _ := proto.Marshal(&msp.SerializedIdentity{Mspid: "Org1MSP", IdBytes: []byte("no certificate")})
+	for _, creator := range [][]byte{[]byte("garbage"), bad} {
+		if _, err := NewClientIdentity(creator); err == nil {
+			t.Errorf("creator %q parsed", creator)
+		}
+	}
+}
@@ -44,20 +44,9 @@ func IntoIdentity(creatorByte []byte) ([]byte, error) {
 
 // IntoAddress computes 160 bits address from the public key encoded in an identity.
 func IntoAddress(creatorByte []byte) (Address, error) {
-	certStart := bytes.Index(creatorByte, []byte("-----BEGIN"))
-	if certStart == -1 {
-		return ZeroAddress, errors.New("no creator certificate found")
-	}
-	certText := creatorByte[certStart:]
-
-	bl, _ := pem.Decode(certText)
-	if bl == nil {
-		return ZeroAddress, errors.New("could not decode the PEM structure")
-	}
-
-	cert, err := x509.ParseCertificate(bl.Bytes)
+	cert, err := creatorCertificate(creatorByte)
 	if err != nil {
-		return ZeroAddress, fmt.Errorf("failed to parse certificate: %s", err)
+		return ZeroAddress, err
 	}
 
 	return PublicKeyIntoAddress(cert.PublicKey)
@@ -0,0 +1,107 @@
+package impl
+
+import (
+	"log"
+	"strings"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/identity"
+)
+
+// ACL is the access control list of a service or method. A caller is
+// allowed if it matches any of the rules: one of the addresses, one of the
+// MSP IDs, all of the certificate attributes or one of the roles granted
+// with contract.GrantRole. An ACL without rules denies every caller.
+type ACL struct {
+	Addresses []string          `json:"addresses,omitempty"`
+	MSPIDs    []string          `json:"mspIds,omitempty"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	Roles     []string          `json:"roles,omitempty"`
+}
+
+// Allow restricts the calls of a registered service, e.g. "MyService", or a
+// method, e.g. "MyService.SetPrice", to the callers matching acl. The ACL of
+// a method replaces the one of its service. Callers are checked inside the
+// interceptors, which see the denied calls, and before the params are
+// decoded.
+func (cc *FabricChaincode) Allow(serviceOrMethod string, acl *ACL) {
+	if acl == nil {
+		panic("impl: Allow needs an ACL")
+	}
+	cc.mustBeRegistered("Allow", serviceOrMethod)
+	if cc.acls == nil {
+		cc.acls = map[string]*ACL{}
+	}
+	cc.acls[serviceOrMethod] = acl
+}
+
+// aclOf returns the ACL applying to method, nil if calls are not restricted.
+func (cc *FabricChaincode) aclOf(method string) *ACL {
+	if acl, ok := cc.acls[method]; ok {
+		return acl
+	}
+	if dot := strings.LastIndex(method, "."); dot >= 0 {
+		return cc.acls[method[:dot]]
+	}
+	return nil
+}
+
+// authorize checks the caller against the ACL of the method. A caller
+// whose identity can not be resolved is denied.
+func (cc *FabricChaincode) authorize(ctx contract.Context) error {
+	acl := cc.aclOf(ctx.Method())
+	if acl == nil {
+		return nil
+	}
+	caller, err := ctx.Caller()
+	if err != nil {
+		log.Printf("ERR: permission denied, method:%s, caller error:%s\n", ctx.Method(), err.Error())
+		return contract.ErrPermissionDenied
+	}
+	ok, err := acl.allows(ctx, caller)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Printf("ERR: permission denied, method:%s, caller:%s\n", ctx.Method(), caller.Address.String())
+		return contract.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (acl *ACL) allows(ctx contract.Context, caller *identity.ClientIdentity) (bool, error) {
+	address := caller.Address.String()
+
+	for _, addr := range acl.Addresses {
+		if strings.EqualFold(addr, address) {
+			return true, nil
+		}
+	}
+	for _, mspID := range acl.MSPIDs {
+		if mspID == caller.MSPID {
+			return true, nil
+		}
+	}
+	if len(acl.Attrs) > 0 {
+		matched := true
+		for name, value := range acl.Attrs {
+			if caller.AssertAttributeValue(name, value) != nil {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	for _, role := range acl.Roles {
+		ok, err := contract.HasRole(ctx.Stub(), role, address)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,138 @@
+package impl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hello/pkg/contract"
+	"hello/pkg/rpc"
+)
+
+type Vault struct{}
+
+func (v *Vault) Open(stub contract.IContractStub) (string, error) {
+	return "open", nil
+}
+
+func (v *Vault) Peek(stub contract.IContractStub) (string, error) {
+	return "peek", nil
+}
+
+var otherAddr = strings.Repeat("0b", 20)
+
+func deployVault(chain *MemoryFactoryChain) *FabricChaincode {
+	cc := NewFabricChaincode()
+	cc.Register(&Vault{})
+	chain.Deploy(defaultChaincode, cc)
+	return cc
+}
+
+func invokeStatus(chain *MemoryFactoryChain, method, addr string, opts ...StubOption) string {
+	resp := chain.Invoke(chain.NewStub(addr, opts...), method)
+	if resp.Status != 200 {
+		return resp.Message
+	}
+	return "OK"
+}
+
+func TestACLRules(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployVault(chain)
+	cc.Allow("Vault", &ACL{
+		Addresses: []string{strings.ToUpper(testAddr)},
+		MSPIDs:    []string{"Org2MSP"},
+		Attrs:     map[string]string{"role": "auditor", "dept": "finance"},
+		Roles:     []string{"keeper"},
+	})
+
+	stub := chain.NewStub(testAddr)
+	if err := contract.GrantRole(stub, "keeper", strings.Repeat("0c", 20)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		addr string
+		opts []StubOption
+		want string
+	}{
+		{"address", testAddr, nil, "OK"},
+		{"msp", otherAddr, []StubOption{WithMSPID("Org2MSP")}, "OK"},
+		{"all attrs", otherAddr, []StubOption{WithAttributes(map[string]string{"role": "auditor", "dept": "finance"})}, "OK"},
+		{"some attrs", otherAddr, []StubOption{WithAttributes(map[string]string{"role": "auditor"})}, contract.ERR_PERMISSION_DENIED},
+		{"role", strings.Repeat("0c", 20), nil, "OK"},
+		{"none", otherAddr, nil, contract.ERR_PERMISSION_DENIED},
+	}
+	for _, c := range cases {
+		if got := invokeStatus(chain, "Vault.Open", c.addr, c.opts...); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestACLMethodReplacesService(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployVault(chain)
+	cc.Allow("Vault", &ACL{Addresses: []string{testAddr}})
+	cc.Allow("Vault.Peek", &ACL{MSPIDs: []string{"Org2MSP"}})
+
+	if got := invokeStatus(chain, "Vault.Open", testAddr); got != "OK" {
+		t.Fatalf("service ACL: got %s", got)
+	}
+	if got := invokeStatus(chain, "Vault.Peek", testAddr); got != contract.ERR_PERMISSION_DENIED {
+		t.Fatalf("method ACL, address of the service ACL: got %s", got)
+	}
+	if got := invokeStatus(chain, "Vault.Peek", otherAddr, WithMSPID("Org2MSP")); got != "OK" {
+		t.Fatalf("method ACL: got %s", got)
+	}
+}
+
+func TestACLEmptyDeniesAll(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployVault(chain)
+	cc.Allow("Vault.Open", &ACL{})
+
+	if got := invokeStatus(chain, "Vault.Open", testAddr); got != contract.ERR_PERMISSION_DENIED {
+		t.Fatalf("got %s", got)
+	}
+	if got := invokeStatus(chain, "Vault.Peek", testAddr); got != "OK" {
+		t.Fatalf("unrestricted method: got %s", got)
+	}
+}
+
+func TestACLCallerError(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployVault(chain)
+	cc.Allow("Vault", &ACL{Addresses: []string{testAddr}})
+
+	ctx := contract.NewContext(chain.NewStub("not an address"), "Vault.Open")
+	if err := cc.authorize(ctx); err != contract.ErrPermissionDenied {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestACLInsideInterceptors(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployVault(chain)
+	cc.Allow("Vault.Open", &ACL{Addresses: []string{testAddr}})
+
+	var denied []string
+	cc.Use(func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		ret, err := next(ctx, req)
+		if err == contract.ErrPermissionDenied {
+			denied = append(denied, req.ServiceMethod)
+		}
+		return ret, err
+	})
+
+	if got := invokeStatus(chain, "Vault.Open", otherAddr); got != contract.ERR_PERMISSION_DENIED {
+		t.Fatalf("got %s", got)
+	}
+	if got := invokeStatus(chain, "Vault.Open", testAddr); got != "OK" {
+		t.Fatalf("allowed caller: got %s", got)
+	}
+	if !reflect.DeepEqual(denied, []string{"Vault.Open"}) {
+		t.Fatalf("denied = %v", denied)
+	}
+}
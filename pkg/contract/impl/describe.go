@@ -0,0 +1,56 @@
+package impl
+
+import (
+	"encoding/json"
+
+	"hello/pkg/rpc"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// DescribeMethod is the reserved query which returns the ChaincodeDesc of a
+// chaincode. It takes precedence over a registered method of the same name.
+const DescribeMethod = "System.Describe"
+
+// ChaincodeDesc describes the API of a chaincode.
+type ChaincodeDesc struct {
+	Version string        `json:"version"`
+	Init    *MethodDesc   `json:"init,omitempty"`
+	Methods []*MethodDesc `json:"methods"`
+}
+
+// MethodDesc describes a method, who may call it and whether it is a query
+// to evaluate rather than submit.
+type MethodDesc struct {
+	*rpc.MethodDesc
+	ACL      *ACL `json:"acl,omitempty"`
+	ReadOnly bool `json:"readOnly"`
+}
+
+// SetVersion sets the version reported by System.Describe.
+func (cc *FabricChaincode) SetVersion(version string) {
+	cc.version = version
+}
+
+// Describe returns the version, the init function and the registered
+// methods with their params, the stub or context not counted.
+func (cc *FabricChaincode) Describe() *ChaincodeDesc {
+	desc := &ChaincodeDesc{Version: cc.version, Methods: []*MethodDesc{}}
+	for _, m := range cc.rpc.Describe() {
+		desc.Methods = append(desc.Methods, &MethodDesc{MethodDesc: m, ACL: cc.aclOf(m.Name), ReadOnly: cc.isReadOnly(m.Name)})
+	}
+	if cc.initRpc != nil {
+		m := cc.initRpc.Describe()[0]
+		desc.Init = &MethodDesc{MethodDesc: m, ACL: cc.aclOf(m.Name)}
+	}
+	return desc
+}
+
+func (cc *FabricChaincode) describe() pb.Response {
+	buf, err := json.Marshal(cc.Describe())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(buf)
+}
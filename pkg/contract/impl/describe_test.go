@@ -0,0 +1,33 @@
+package impl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSystemDescribe(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+	cc.SetVersion("1.2.0")
+
+	resp := chain.Invoke(chain.NewStub(testAddr), DescribeMethod)
+	if resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	desc := &ChaincodeDesc{}
+	if err := json.Unmarshal(resp.Payload, desc); err != nil {
+		t.Fatal(err)
+	}
+	if desc.Version != "1.2.0" {
+		t.Fatalf("version = %q", desc.Version)
+	}
+	if desc.Init == nil || desc.Init.Name != initServiceMethod || len(desc.Init.Params) != 1 {
+		t.Fatalf("init = %+v", desc.Init)
+	}
+	if len(desc.Methods) != 1 || desc.Methods[0].Name != "Token.Supply" || len(desc.Methods[0].Params) != 0 {
+		t.Fatalf("methods = %s", resp.Payload)
+	}
+	if desc.Methods[0].Returns["type"] != "string" {
+		t.Fatalf("returns = %v", desc.Methods[0].Returns)
+	}
+}
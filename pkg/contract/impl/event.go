@@ -0,0 +1,51 @@
+package impl
+
+import (
+	"encoding/json"
+
+	"hello/pkg/contract"
+)
+
+// eventAccumulator is implemented by stubs which collect the events added
+// during an invocation.
+type eventAccumulator interface {
+	takeEvents() []*contract.Event
+	// lastEvent returns the event set with SetEvent, if any.
+	lastEvent() (name string, payload []byte, ok bool)
+}
+
+// flushEvents sets the events added to the stub as one envelope event. An
+// event set directly with SetEvent becomes the first event of the envelope
+// instead of being replaced by it.
+func flushEvents(stub contract.IContractStub) error {
+	acc, ok := stub.(eventAccumulator)
+	if !ok {
+		return nil
+	}
+	events := acc.takeEvents()
+	if len(events) == 0 {
+		return nil
+	}
+	if name, payload, ok := acc.lastEvent(); ok {
+		var set []*contract.Event
+		if name == contract.EventEnvelopeName {
+			prev, err := contract.DecodeEventEnvelope(payload)
+			if err != nil {
+				return err
+			}
+			set = prev
+		} else {
+			e, err := contract.WrapEvent(name, payload)
+			if err != nil {
+				return err
+			}
+			set = []*contract.Event{e}
+		}
+		events = append(set, events...)
+	}
+	payload, err := json.Marshal(&contract.EventEnvelope{Events: events})
+	if err != nil {
+		return err
+	}
+	return stub.SetEvent(contract.EventEnvelopeName, payload)
+}
@@ -0,0 +1,116 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+
+	"hello/pkg/contract"
+)
+
+type Emitter struct{}
+
+func (e *Emitter) Create(stub contract.IContractStub) (bool, error) {
+	if err := contract.CreateEvent(stub, "app", "first", []byte("raw")); err != nil {
+		return false, err
+	}
+	return true, contract.CreateEvent(stub, "app", "second", []byte("raw"))
+}
+
+func (e *Emitter) Add(stub contract.IContractStub) (bool, error) {
+	if err := contract.AddEvent(stub, "app", "first", map[string]int{"n": 1}); err != nil {
+		return false, err
+	}
+	return true, contract.AddEvent(stub, "app", "second", map[string]int{"n": 2})
+}
+
+func (e *Emitter) CreateAndAdd(stub contract.IContractStub) (bool, error) {
+	if err := contract.CreateEvent(stub, "app", "created", []byte(`{"n":1}`)); err != nil {
+		return false, err
+	}
+	return true, contract.AddEvent(stub, "app", "added", 2)
+}
+
+func (e *Emitter) SetAndAdd(stub contract.IContractStub) (bool, error) {
+	if err := stub.SetEvent("legacy", []byte("raw")); err != nil {
+		return false, err
+	}
+	return true, contract.AddEvent(stub, "app", "added", 1)
+}
+
+func (e *Emitter) SetOnly(stub contract.IContractStub) (bool, error) {
+	return true, stub.SetEvent("legacy", []byte("raw"))
+}
+
+// emitted invokes method of an Emitter and returns the events of the chain.
+func emitted(t *testing.T, chain *MemoryFactoryChain, method string) []*ChaincodeEvent {
+	t.Helper()
+	cc := NewFabricChaincode()
+	cc.Register(&Emitter{})
+	chain.Deploy(defaultChaincode, cc)
+	if resp := chain.Invoke(chain.NewStub(testAddr), method); resp.Status != 200 {
+		t.Fatal(resp.Message)
+	}
+	return chain.Events(EventFilter{})
+}
+
+func envelopeOf(t *testing.T, chain *MemoryFactoryChain, method string) []*contract.Event {
+	t.Helper()
+	events := emitted(t, chain, method)
+	if len(events) != 1 || events[0].EventName != contract.EventEnvelopeName {
+		t.Fatalf("got %q", eventNames(events))
+	}
+	envelope, err := contract.DecodeEventEnvelope(events[0].Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func namesOf(events []*contract.Event) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Name+" "+string(e.Payload))
+	}
+	return names
+}
+
+func TestCreateEvent(t *testing.T) {
+	events := emitted(t, NewMemoryFactoryChain(), "Emitter.Create")
+	if len(events) != 1 || events[0].EventName != "app.second" || string(events[0].Payload) != "raw" {
+		t.Fatalf("got %q", eventNames(events))
+	}
+}
+
+func TestFlushEvents(t *testing.T) {
+	got := namesOf(envelopeOf(t, NewMemoryFactoryChain(), "Emitter.Add"))
+	want := []string{`app.first {"n":1}`, `app.second {"n":2}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestFlushEventsKeepsSetEvent(t *testing.T) {
+	for _, chain := range []*MemoryFactoryChain{NewMemoryFactoryChain(), NewMemoryFactoryChain(WithTxSimulation())} {
+		got := namesOf(envelopeOf(t, chain, "Emitter.SetAndAdd"))
+		want := []string{`legacy "cmF3"`, `app.added 1`}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFlushEventsKeepsCreateEvent(t *testing.T) {
+	got := namesOf(envelopeOf(t, NewMemoryFactoryChain(), "Emitter.CreateAndAdd"))
+	want := []string{`app.created {"n":1}`, `app.added 2`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestFlushEventsWithoutAddedEvents(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	events := emitted(t, chain, "Emitter.SetOnly")
+	if len(events) != 1 || events[0].EventName != "legacy" || string(events[0].Payload) != "raw" {
+		t.Fatalf("got %q", eventNames(events))
+	}
+}
@@ -0,0 +1,371 @@
+package impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"hello/pkg/contract"
+	"hello/pkg/rpc"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// initServiceMethod is the name of the function registered with RegisterInit.
+const initServiceMethod = "Chaincode.Init"
+
+type FabricChaincode struct {
+	rpc      rpc.Rpc
+	initRpc  rpc.Rpc       // holds the init function, see RegisterInit
+	initFunc string        // name of the init function, see funcName
+	upgrade  string        // see UpgradeInit
+	services []interface{} // receivers passed to Register
+	version  string
+
+	interceptors []Interceptor
+	scoped       map[string][]Interceptor // service or "Service.Method" -> interceptors
+	acls         map[string]*ACL          // service or "Service.Method" -> ACL
+	readOnly     map[string]bool          // service or "Service.Method" -> query only
+}
+
+// baseTypes are the first argument a method can take, see newRpc.
+var baseTypes = []reflect.Type{
+	reflect.TypeOf((*contract.IContractStub)(nil)).Elem(),
+	reflect.TypeOf((*contract.Context)(nil)).Elem(),
+}
+
+// newRpc returns an RPC whose methods take the stub or a contract.Context.
+func newRpc() rpc.Rpc {
+	return rpc.New(baseTypes...)
+}
+
+func NewFabricChaincode() *FabricChaincode {
+	return &FabricChaincode{rpc: newRpc()}
+}
+
+// Init runs the function registered with RegisterInit once per ledger, or
+// once more for an upgrade named with UpgradeInit. The arguments are like
+// the ones of Invoke but the function name is ignored. The peer calls Init
+// for instantiation and for an invocation with isInit when the chaincode is
+// approved with --init-required. Without an init function Init succeeds.
+func (cc *FabricChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc.init(NewFabricContractStub(stub))
+}
+
+// initRecord is stored when the init function ran.
+type initRecord struct {
+	TxID    string `json:"txId"`
+	Upgrade string `json:"upgrade,omitempty"`
+}
+
+func (cc *FabricChaincode) init(stb contract.IContractStub) pb.Response {
+	if cc.initRpc == nil {
+		return shim.Success([]byte("SUCCESS"))
+	}
+
+	args := stb.GetArgs()
+	if len(args) == 0 {
+		args = [][]byte{[]byte(initServiceMethod)}
+	}
+	req, errMsg := readRequest(stb, args)
+	if req == nil {
+		return shim.Error(errMsg)
+	}
+	req.ServiceMethod = initServiceMethod
+
+	key, err := contract.CreateCompositeKey("coral", []string{"init"})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	buf, err := stb.GetState(key)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if buf != nil {
+		// a record which can not be read counts as one of this upgrade
+		var prev initRecord
+		if json.Unmarshal(buf, &prev) != nil || prev.Upgrade == cc.upgrade {
+			return shim.Error(contract.ERR_ALREADY_INITIALIZED)
+		}
+	}
+	buf, err = json.Marshal(&initRecord{TxID: stb.GetTxID(), Upgrade: cc.upgrade})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err = stb.PutState(key, buf); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return cc.handler(cc.initRpc, stb, req)
+}
+
+func (cc *FabricChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc.invoke(NewFabricContractStub(stub))
+}
+
+func (cc *FabricChaincode) invoke(stb contract.IContractStub) pb.Response {
+	req, errMsg := readRequest(stb, stb.GetArgs())
+	if req == nil {
+		return shim.Error(errMsg)
+	}
+	if req.ServiceMethod == DescribeMethod {
+		return cc.describe()
+	}
+	return cc.handler(cc.rpc, stb, req)
+}
+
+// readRequest parses the arguments, the method name and optionally the
+// params as JSON array. It returns the error message on failure.
+func readRequest(stb contract.IContractStub, args [][]byte) (*rpc.Request, string) {
+	if len(args) <= 0 || len(args) > 2 {
+		return nil, contract.ERR_PARAM_INVALID
+	}
+
+	method := string(args[0])
+	var (
+		param []*json.RawMessage
+		named map[string]*json.RawMessage
+	)
+
+	if len(args) == 2 {
+		var err error
+		// params are either an array or an object keyed by param name
+		if body := bytes.TrimSpace(args[1]); len(body) > 0 && body[0] == '{' {
+			err = json.Unmarshal(body, &named)
+		} else {
+			err = json.Unmarshal(args[1], &param)
+		}
+		if err != nil {
+			log.Printf("ERR: json.Unmarshal error:%s, date:%s\n", err.Error(), string(args[1]))
+			return nil, contract.ERR_JSON_UNMARSHAL
+		}
+	}
+
+	addr, err := stb.GetAddress()
+	if err != nil {
+		log.Printf("ERR: auth user failed, error:%s\n", err.Error())
+		return nil, "ERR_INVALID_CERT"
+	}
+
+	if named != nil {
+		log.Printf("INFO: address:%s, method:%s, params:%v\n", addr, method, named)
+	} else {
+		log.Printf("INFO: address:%s, method:%s, params:%v\n", addr, method, param)
+	}
+
+	transient, err := stb.GetTransient()
+	if err != nil {
+		log.Printf("ERR: get transient failed, error:%s\n", err.Error())
+		return nil, contract.ERR_PARAM_INVALID
+	}
+
+	req := &rpc.Request{
+		ServiceMethod: method,
+		Params:        param,
+		Transient:     transient,
+		NamedParams:   named,
+	}
+	return req, ""
+}
+
+func (cc *FabricChaincode) handler(r rpc.Rpc, stub contract.IContractStub, req *rpc.Request) pb.Response {
+	var (
+		ret interface{}
+		err error
+	)
+
+	startTime := time.Now()
+
+	tx, _ := stub.(transaction)
+
+	ret, err = cc.recoverHandler(r, stub, req)
+	if err != nil {
+		log.Printf("ERR:response error:%s\n", err.Error())
+		if tx != nil {
+			tx.rollback()
+		}
+		return shim.Error(err.Error())
+	}
+
+	var buf []byte
+	if ret != nil {
+		buf, err = json.Marshal(ret)
+		if err != nil {
+			log.Printf("ERR:response error:%s\n", err.Error())
+			if tx != nil {
+				tx.rollback()
+			}
+			return shim.Error(contract.ERR_JSON_MARSHAL)
+		}
+	}
+
+	if err = flushEvents(stub); err != nil {
+		log.Printf("ERR:event error:%s\n", err.Error())
+		if tx != nil {
+			tx.rollback()
+		}
+		return shim.Error(err.Error())
+	}
+
+	if tx != nil {
+		if err = tx.commit(); err != nil {
+			log.Printf("ERR:commit error:%s\n", err.Error())
+			return shim.Error(err.Error())
+		}
+	}
+
+	if buf == nil {
+		log.Printf("INFO: process takes %v, response success:null\n", time.Since(startTime))
+		return shim.Success(nil)
+	}
+	log.Printf("INFO: process takes %v, response success:%s\n", time.Since(startTime), string(buf))
+	return shim.Success(buf)
+}
+
+func (cc *FabricChaincode) recoverHandler(r rpc.Rpc, stub contract.IContractStub, req *rpc.Request) (ret interface{}, err error) {
+	defer func() {
+		if re := recover(); re != nil {
+			switch v := re.(type) {
+			case contract.InternalError:
+				//logger.Error(v.Error())
+				err = errors.New(v.External())
+			case *contract.InternalError:
+				//logger.Error(v.Error())
+				err = errors.New(v.External())
+			case string:
+				err = errors.New(v)
+			case runtime.Error:
+				fmt.Printf("ERR: runtime error:%v \nstack :%s\n", v, string(debug.Stack()))
+				err = contract.ErrRuntime
+			case error:
+				err = v
+			default:
+				err = fmt.Errorf("ERR: ohter error type:%v, value:%v", reflect.TypeOf(re), v)
+			}
+		}
+	}()
+
+	h := cc.chain(req.ServiceMethod, func(ctx contract.Context, req *rpc.Request) (interface{}, error) {
+		if err := cc.authorize(ctx); err != nil {
+			return nil, err
+		}
+		return r.Handler(req, ctx.Stub(), ctx)
+	})
+	if cc.isReadOnly(req.ServiceMethod) {
+		stub = &readOnlyStub{IContractStub: stub}
+	}
+	ret, err = h(contract.NewContext(stub, req.ServiceMethod), req)
+	return
+}
+
+// Register registers the methods of i. The method bound with RegisterInit
+// is left out, so that it can only run through Init.
+func (cc *FabricChaincode) Register(i interface{}) {
+	err := cc.rpc.Register(i)
+	if err != nil {
+		panic(err)
+	}
+	cc.services = append(cc.services, i)
+	cc.unregisterInit(i)
+}
+
+// RegisterInit registers fn, e.g. the method value svc.Init, as the init
+// function. It takes the stub or context and params like a registered method.
+// If fn is a method of a service passed to Register, before or after, the
+// method can not be invoked.
+func (cc *FabricChaincode) RegisterInit(fn interface{}, opts ...rpc.Option) {
+	r := newRpc()
+	err := r.RegisterFunc(initServiceMethod, fn)
+	if err == nil {
+		err = r.Configure(initServiceMethod, opts...)
+	}
+	if err != nil {
+		panic(err)
+	}
+	cc.initRpc = r
+	cc.initFunc = funcName(reflect.ValueOf(fn))
+	for _, i := range cc.services {
+		cc.unregisterInit(i)
+	}
+}
+
+// UpgradeInit lets Init run once more on a ledger where it ran before, e.g.
+// when Fabric 1.x calls Init for a chaincode upgrade. Init runs once for
+// every name passed in a new release of the chaincode.
+func (cc *FabricChaincode) UpgradeInit(name string) {
+	cc.upgrade = name
+}
+
+// unregisterInit removes the method of the service i which is the init
+// function.
+func (cc *FabricChaincode) unregisterInit(i interface{}) {
+	if cc.initFunc == "" {
+		return
+	}
+	typ := reflect.TypeOf(i)
+	sname := reflect.Indirect(reflect.ValueOf(i)).Type().Name()
+	for m := 0; m < typ.NumMethod(); m++ {
+		method := typ.Method(m)
+		if method.PkgPath != "" || funcName(method.Func) != cc.initFunc {
+			continue
+		}
+		if err := cc.rpc.Unregister(sname + "." + method.Name); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// funcName returns the name of a function, e.g. "pkg.Svc.Init" for the
+// method value svc.Init and the method (*Svc).Init alike.
+func funcName(fn reflect.Value) string {
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// mustBeRegistered panics unless name is a registered service or method,
+// the init function included, so that a typo does not leave a method
+// unprotected.
+func (cc *FabricChaincode) mustBeRegistered(fn, name string) {
+	if name == "" {
+		panic(fmt.Sprintf("impl: %s needs a service or method", fn))
+	}
+	descs := cc.rpc.Describe()
+	if cc.initRpc != nil {
+		descs = append(descs, cc.initRpc.Describe()...)
+	}
+	for _, d := range descs {
+		if d.Name == name || d.Name[:strings.LastIndex(d.Name, ".")] == name {
+			return
+		}
+	}
+	panic(fmt.Sprintf("impl: %s: %s is not a registered service or method", fn, name))
+}
+
+// Configure sets options on a registered method, e.g.
+//
+//	cc.Configure("MyService.SetPrice", rpc.Transient(1, "price"))
+func (cc *FabricChaincode) Configure(serviceMethod string, opts ...rpc.Option) {
+	err := cc.rpc.Configure(serviceMethod, opts...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cc *FabricChaincode) Start() {
+	err := shim.Start(cc)
+	if err != nil {
+		panic("Error starting chaincode - " + err.Error())
+	}
+}
@@ -0,0 +1,131 @@
+package impl
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"hello/pkg/contract"
+)
+
+type Token struct{}
+
+func (t *Token) Init(stub contract.IContractStub, supply int) (bool, error) {
+	if err := stub.PutState("supply", []byte(strconv.Itoa(supply))); err != nil {
+		return false, err
+	}
+	if supply < 0 {
+		return false, errors.New("supply must not be negative")
+	}
+	return true, nil
+}
+
+func (t *Token) Supply(stub contract.IContractStub) (string, error) {
+	v, err := stub.GetState("supply")
+	return string(v), err
+}
+
+// deployToken deploys a chaincode with Token.Init as init function,
+// registered before or after the service.
+func deployToken(chain *MemoryFactoryChain, initFirst bool) *FabricChaincode {
+	token := &Token{}
+	cc := NewFabricChaincode()
+	if initFirst {
+		cc.RegisterInit(token.Init)
+		cc.Register(token)
+	} else {
+		cc.Register(token)
+		cc.RegisterInit(token.Init)
+	}
+	chain.Deploy(defaultChaincode, cc)
+	return cc
+}
+
+func TestInitOnce(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	deployToken(chain, true)
+
+	if resp := chain.Init(chain.NewStub(testAddr), 10); resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	resp := chain.Init(chain.NewStub(testAddr), 20)
+	if resp.Status == 200 || resp.Message != contract.ERR_ALREADY_INITIALIZED {
+		t.Fatalf("second init: got %d %s", resp.Status, resp.Message)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "supply"); got != "10" {
+		t.Fatalf("supply = %q", got)
+	}
+}
+
+func TestInitNotInvokable(t *testing.T) {
+	for _, initFirst := range []bool{true, false} {
+		chain := NewMemoryFactoryChain()
+		cc := deployToken(chain, initFirst)
+
+		resp := chain.Invoke(chain.NewStub(testAddr), "Token.Init", 20)
+		if resp.Status == 200 {
+			t.Fatalf("initFirst %v: Token.Init was invoked", initFirst)
+		}
+		if resp := chain.Invoke(chain.NewStub(testAddr), "Token.Supply"); resp.Status != 200 {
+			t.Fatalf("initFirst %v: got %d %s", initFirst, resp.Status, resp.Message)
+		}
+		for _, m := range cc.Describe().Methods {
+			if m.Name == "Token.Init" {
+				t.Fatalf("initFirst %v: Token.Init is described", initFirst)
+			}
+		}
+	}
+}
+
+func TestInitRollback(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	deployToken(chain, true)
+
+	if resp := chain.Init(chain.NewStub(testAddr), -1); resp.Status == 200 {
+		t.Fatal("init with a negative supply succeeded")
+	}
+	if resp := chain.Init(chain.NewStub(testAddr), 5); resp.Status != 200 {
+		t.Fatalf("init after a failed one: got %d %s", resp.Status, resp.Message)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "supply"); got != "5" {
+		t.Fatalf("supply = %q", got)
+	}
+}
+
+func TestInitUpgrade(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+
+	if resp := chain.Init(chain.NewStub(testAddr), 10); resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	cc.SetVersion("1.1")
+	if resp := chain.Init(chain.NewStub(testAddr), 20); resp.Message != contract.ERR_ALREADY_INITIALIZED {
+		t.Fatalf("new version without upgrade: got %d %s", resp.Status, resp.Message)
+	}
+
+	cc.UpgradeInit("1.1")
+	if resp := chain.Init(chain.NewStub(testAddr), 30); resp.Status != 200 {
+		t.Fatalf("upgrade: got %d %s", resp.Status, resp.Message)
+	}
+	if resp := chain.Init(chain.NewStub(testAddr), 40); resp.Message != contract.ERR_ALREADY_INITIALIZED {
+		t.Fatalf("same upgrade: got %d %s", resp.Status, resp.Message)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "supply"); got != "30" {
+		t.Fatalf("supply = %q", got)
+	}
+}
+
+func TestInitWithoutInitFunction(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := NewFabricChaincode()
+	cc.Register(&Counter{})
+	chain.Deploy(defaultChaincode, cc)
+
+	for _, params := range [][]interface{}{nil, {1, "a"}} {
+		resp := chain.Init(chain.NewStub(testAddr), params...)
+		if resp.Status != 200 || string(resp.Payload) != "SUCCESS" {
+			t.Fatalf("params %v: got %d %s", params, resp.Status, resp.Message)
+		}
+	}
+}
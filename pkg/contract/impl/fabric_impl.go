@@ -0,0 +1,268 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/identity"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+type FabricContractStub struct {
+	stub    shim.ChaincodeStubInterface
+	creator func() []byte
+	events  []*contract.Event
+	event   *fabricEvent // set with SetEvent, see flushEvents
+}
+
+// fabricEvent is the event set on the shim stub when the invocation
+// completes.
+type fabricEvent struct {
+	name    string
+	payload []byte
+}
+
+func NewFabricContractStub(stub shim.ChaincodeStubInterface) contract.IContractStub {
+	return &FabricContractStub{stub: stub}
+}
+
+func (f *FabricContractStub) setCreatorFactory(creator func() []byte) {
+	f.creator = creator
+}
+
+func (f *FabricContractStub) GetArgs() [][]byte {
+	return f.stub.GetArgs()
+}
+
+func (f *FabricContractStub) GetTxID() string {
+	return f.stub.GetTxID()
+}
+
+func (f *FabricContractStub) GetChannelID() string {
+	return f.stub.GetChannelID()
+}
+
+func (f *FabricContractStub) GetAddress() (string, error) {
+	creatorByte, err := f.stub.GetCreator()
+	if err != nil {
+		return "", err
+	}
+	addr, err := identity.IntoAddress(creatorByte)
+	if err != nil {
+		return "", err
+	}
+
+	return addr.String(), nil
+}
+
+func (f *FabricContractStub) GetClientIdentity() (*identity.ClientIdentity, error) {
+	creatorByte, err := f.stub.GetCreator()
+	if err != nil {
+		return nil, err
+	}
+	return identity.NewClientIdentity(creatorByte)
+}
+
+func (f *FabricContractStub) GetTransient() (map[string][]byte, error) {
+	return f.stub.GetTransient()
+}
+
+func (f *FabricContractStub) GetState(key string) ([]byte, error) {
+	return f.stub.GetState(key)
+}
+
+func (f *FabricContractStub) PutState(key string, value []byte) error {
+	return f.stub.PutState(key, value)
+}
+
+func (f *FabricContractStub) DelState(key string) ([]byte, error) {
+	buf, err := f.stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	err = f.stub.DelState(key)
+	return buf, err
+}
+
+func (f *FabricContractStub) GetStateValidationParameter(key string) ([]byte, error) {
+	return f.stub.GetStateValidationParameter(key)
+}
+
+func (f *FabricContractStub) SetStateValidationParameter(key string, ep []byte) error {
+	return f.stub.SetStateValidationParameter(key, ep)
+}
+
+func (f *FabricContractStub) GetStateByRange(startKey, endKey string) (contract.IStateIterator, error) {
+	iter, err := f.stub.GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	return newFabricStateIterator(iter), nil
+}
+
+func (f *FabricContractStub) GetStateByPartialCompositeKey(objectType string, keys []string) (contract.IStateIterator, error) {
+	iter, err := f.stub.GetStateByPartialCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return newFabricStateIterator(iter), nil
+}
+
+func (f *FabricContractStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	iter, meta, err := f.stub.GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newFabricStateIterator(iter), newQueryMetadata(meta), nil
+}
+
+func (f *FabricContractStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	iter, meta, err := f.stub.GetStateByPartialCompositeKeyWithPagination(objectType, keys, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newFabricStateIterator(iter), newQueryMetadata(meta), nil
+}
+
+func (f *FabricContractStub) GetQueryResult(query string) (contract.IStateIterator, error) {
+	iter, err := f.stub.GetQueryResult(query)
+	if err != nil {
+		return nil, err
+	}
+	return newFabricStateIterator(iter), nil
+}
+
+func (f *FabricContractStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	iter, meta, err := f.stub.GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newFabricStateIterator(iter), newQueryMetadata(meta), nil
+}
+
+func (f *FabricContractStub) GetHistoryForKey(key string) (contract.IHistoryIterator, error) {
+	iter, err := f.stub.GetHistoryForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &fabricHistoryIterator{iter: iter}, nil
+}
+
+func (f *FabricContractStub) GetPrivateData(collection, key string) ([]byte, error) {
+	return f.stub.GetPrivateData(collection, key)
+}
+
+func (f *FabricContractStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	return f.stub.GetPrivateDataHash(collection, key)
+}
+
+func (f *FabricContractStub) PutPrivateData(collection, key string, value []byte) error {
+	return f.stub.PutPrivateData(collection, key, value)
+}
+
+func (f *FabricContractStub) DelPrivateData(collection, key string) error {
+	return f.stub.DelPrivateData(collection, key)
+}
+
+func (f *FabricContractStub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return f.stub.GetPrivateDataValidationParameter(collection, key)
+}
+
+func (f *FabricContractStub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return f.stub.SetPrivateDataValidationParameter(collection, key, ep)
+}
+
+func (f *FabricContractStub) GetPrivateDataByRange(collection, startKey, endKey string) (contract.IStateIterator, error) {
+	iter, err := f.stub.GetPrivateDataByRange(collection, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	return newFabricStateIterator(iter), nil
+}
+
+func (f *FabricContractStub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (contract.IStateIterator, error) {
+	iter, err := f.stub.GetPrivateDataByPartialCompositeKey(collection, objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return newFabricStateIterator(iter), nil
+}
+
+func (f *FabricContractStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return f.stub.CreateCompositeKey(objectType, attributes)
+}
+
+func (f *FabricContractStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return f.stub.SplitCompositeKey(compositeKey)
+}
+
+func (f *FabricContractStub) GetTxTimestamp() (time.Time, error) {
+	ts, err := f.stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return toTime(ts)
+}
+
+func (f *FabricContractStub) SetEvent(name string, payload []byte) error {
+	if err := f.stub.SetEvent(name, payload); err != nil {
+		return err
+	}
+	f.event = &fabricEvent{name: name, payload: payload}
+	return nil
+}
+
+func (f *FabricContractStub) AddEvent(name string, payload []byte) error {
+	e, err := contract.NewEvent(name, payload)
+	if err != nil {
+		return err
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *FabricContractStub) takeEvents() []*contract.Event {
+	events := f.events
+	f.events = nil
+	return events
+}
+
+func (f *FabricContractStub) lastEvent() (string, []byte, bool) {
+	if f.event == nil {
+		return "", nil, false
+	}
+	return f.event.name, f.event.payload, true
+}
+
+func (f *FabricContractStub) InvokeContract(contractName string, args [][]byte, channel string) ([]byte, error) {
+	resp := f.stub.InvokeChaincode(contractName, args, channel)
+	if resp.Status != 200 {
+		return nil, errors.New(resp.Message)
+	}
+	return resp.Payload, nil
+}
+
+func (f *FabricContractStub) GetOriginStub() interface{} {
+	return f.stub
+}
+
+func toTime(ts *timestamp.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, errors.New("timestamp: nil Timestamp")
+	}
+	if ts.Seconds < -62135596800 {
+		return time.Time{}, fmt.Errorf("timestamp: %v before 0001-01-01", ts)
+	}
+	if ts.Seconds >= 253402300800 {
+		return time.Time{}, fmt.Errorf("timestamp: %v after 10000-01-01", ts)
+	}
+	if ts.Nanos < 0 || ts.Nanos >= 1e9 {
+		return time.Time{}, fmt.Errorf("timestamp: %v: nanos not in range [0, 1e9)", ts)
+	}
+
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
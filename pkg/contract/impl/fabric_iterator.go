@@ -0,0 +1,63 @@
+package impl
+
+import (
+	"hello/pkg/contract"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+type fabricStateIterator struct {
+	iter shim.StateQueryIteratorInterface
+}
+
+func newFabricStateIterator(iter shim.StateQueryIteratorInterface) contract.IStateIterator {
+	return &fabricStateIterator{iter: iter}
+}
+
+func (f *fabricStateIterator) HasNext() bool {
+	return f.iter.HasNext()
+}
+
+func (f *fabricStateIterator) Next() (*contract.StateKV, error) {
+	kv, err := f.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+	return &contract.StateKV{Key: kv.Key, Value: kv.Value}, nil
+}
+
+func (f *fabricStateIterator) Close() error {
+	return f.iter.Close()
+}
+
+type fabricHistoryIterator struct {
+	iter shim.HistoryQueryIteratorInterface
+}
+
+func (f *fabricHistoryIterator) HasNext() bool {
+	return f.iter.HasNext()
+}
+
+func (f *fabricHistoryIterator) Next() (*contract.KeyModification, error) {
+	km, err := f.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+	t, err := toTime(km.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return &contract.KeyModification{TxID: km.TxId, Timestamp: t, Value: km.Value, IsDelete: km.IsDelete}, nil
+}
+
+func (f *fabricHistoryIterator) Close() error {
+	return f.iter.Close()
+}
+
+func newQueryMetadata(meta *pb.QueryResponseMetadata) *contract.QueryMetadata {
+	if meta == nil {
+		return &contract.QueryMetadata{}
+	}
+	return &contract.QueryMetadata{FetchedRecordsCount: meta.FetchedRecordsCount, Bookmark: meta.Bookmark}
+}
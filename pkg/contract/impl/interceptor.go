@@ -0,0 +1,50 @@
+package impl
+
+import (
+	"strings"
+
+	"hello/pkg/contract"
+	"hello/pkg/rpc"
+)
+
+// Handler dispatches a call, see Interceptor.
+type Handler func(ctx contract.Context, req *rpc.Request) (interface{}, error)
+
+// Interceptor wraps the dispatch of a call. It may inspect or change the
+// request, return early with an error, or call next and shape its result.
+// Panics are recovered like the ones of the methods.
+type Interceptor func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error)
+
+// Use adds interceptors run for every call, the first one outermost.
+func (cc *FabricChaincode) Use(interceptors ...Interceptor) {
+	cc.interceptors = append(cc.interceptors, interceptors...)
+}
+
+// UseFor adds interceptors run for the calls of a registered service, e.g.
+// "MyService", or a method, e.g. "MyService.SayHello". They run inside the
+// ones added by Use, service interceptors outside method interceptors.
+func (cc *FabricChaincode) UseFor(serviceOrMethod string, interceptors ...Interceptor) {
+	cc.mustBeRegistered("UseFor", serviceOrMethod)
+	if cc.scoped == nil {
+		cc.scoped = map[string][]Interceptor{}
+	}
+	cc.scoped[serviceOrMethod] = append(cc.scoped[serviceOrMethod], interceptors...)
+}
+
+// chain wraps h with the interceptors applying to method.
+func (cc *FabricChaincode) chain(method string, h Handler) Handler {
+	var interceptors []Interceptor
+	interceptors = append(interceptors, cc.interceptors...)
+	if dot := strings.LastIndex(method, "."); dot >= 0 {
+		interceptors = append(interceptors, cc.scoped[method[:dot]]...)
+	}
+	interceptors = append(interceptors, cc.scoped[method]...)
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], h
+		h = func(ctx contract.Context, req *rpc.Request) (interface{}, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return h
+}
@@ -0,0 +1,107 @@
+package impl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hello/pkg/contract"
+	"hello/pkg/rpc"
+)
+
+// trace returns an interceptor which records its name before and after the
+// call.
+func trace(calls *[]string, name string) Interceptor {
+	return func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		*calls = append(*calls, name+">")
+		ret, err := next(ctx, req)
+		*calls = append(*calls, "<"+name)
+		return ret, err
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+
+	var calls []string
+	cc.UseFor("Token.Supply", trace(&calls, "method"))
+	cc.UseFor("Token", trace(&calls, "service"))
+	cc.Use(trace(&calls, "global1"), trace(&calls, "global2"))
+
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Token.Supply"); resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	want := []string{"global1>", "global2>", "service>", "method>", "<method", "<service", "<global2", "<global1"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v", calls)
+	}
+}
+
+func TestInterceptorScope(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+	counter := NewFabricChaincode()
+	counter.Register(&Counter{})
+	chain.Deploy("counter", counter)
+
+	var calls []string
+	counter.UseFor("Counter.Inc", trace(&calls, "inc"))
+	cc.UseFor("Token", trace(&calls, "token"))
+
+	chain.Invoke(chain.NewStub(testAddr), "Token.Supply")
+	chain.Invoke(chain.NewStub(testAddr, WithChaincode("counter")), "Counter.Inc", "n")
+	if want := []string{"token>", "<token", "inc>", "<inc"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v", calls)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+	cc.Use(func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		if ctx.Method() == "Token.Supply" {
+			return nil, errors.New("ERR_MAINTENANCE")
+		}
+		return next(ctx, req)
+	})
+
+	resp := chain.Invoke(chain.NewStub(testAddr), "Token.Supply")
+	if resp.Status == 200 || resp.Message != "ERR_MAINTENANCE" {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+}
+
+func TestInterceptorShapesResult(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+	cc.Use(func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		ret, err := next(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"data": ret}, nil
+	})
+	if resp := chain.Init(chain.NewStub(testAddr), 7); resp.Status != 200 {
+		t.Fatalf("init: got %d %s", resp.Status, resp.Message)
+	}
+
+	resp := chain.Invoke(chain.NewStub(testAddr), "Token.Supply")
+	if resp.Status != 200 || string(resp.Payload) != `{"data":"7"}` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestInterceptorPanic(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployToken(chain, true)
+	cc.Use(func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		panic("ERR_INTERCEPTOR")
+	})
+
+	resp := chain.Invoke(chain.NewStub(testAddr), "Token.Supply")
+	if resp.Status == 200 || !strings.Contains(resp.Message, "ERR_INTERCEPTOR") {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+}
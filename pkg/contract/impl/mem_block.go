@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"errors"
+
+	"hello/pkg/contract"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// TxResult is the validation result of a transaction in a block.
+type TxResult struct {
+	TxID string
+	Code pb.TxValidationCode
+}
+
+// MemoryBlock simulates concurrent transactions: stubs created by the block
+// all read the world state as of the last block, and their read/write sets
+// are validated in order when the block is committed.
+type MemoryBlock struct {
+	factory   *MemoryFactoryChain
+	txs       []*memoryStub
+	committed bool
+}
+
+// NewBlock starts a block on the chain. No other transaction should be
+// committed on the chain until the block is.
+func (m *MemoryFactoryChain) NewBlock() *MemoryBlock {
+	return &MemoryBlock{factory: m}
+}
+
+// NewStub creates a stub like MemoryFactoryChain.NewStub whose transaction
+// joins the block instead of being committed immediately.
+func (b *MemoryBlock) NewStub(addr string, opts ...StubOption) contract.IContractStub {
+	stub := b.factory.NewStub(addr, opts...).(*memoryStub)
+	stub.block = b
+	return stub
+}
+
+// submit queues a copy of the transaction of the stub, which goes on with
+// the next one.
+func (b *MemoryBlock) submit(stub *memoryStub) error {
+	if b.committed {
+		return errors.New("block already committed")
+	}
+	tx := *stub
+	tx.tx = stub.tx.clone()
+	b.txs = append(b.txs, &tx)
+	stub.reset()
+	return nil
+}
+
+// Commit validates the transactions in the order they were submitted, each
+// against the world state left by the valid transactions before it. Writes
+// of transactions which read a key or range changed since are dropped.
+func (b *MemoryBlock) Commit() []*TxResult {
+	if b.committed {
+		return nil
+	}
+	b.committed = true
+	b.factory.height++
+
+	results := make([]*TxResult, 0, len(b.txs))
+	for i, stub := range b.txs {
+		code := stub.validate()
+		if code == pb.TxValidationCode_VALID {
+			stub.apply(&memoryVersion{BlockNum: b.factory.height, TxNum: uint64(i)})
+		}
+		results = append(results, &TxResult{TxID: stub.GetTxID(), Code: code})
+	}
+	return results
+}
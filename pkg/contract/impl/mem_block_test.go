@@ -0,0 +1,151 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+
+	"hello/pkg/contract"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func codesOf(results []*TxResult) []pb.TxValidationCode {
+	var codes []pb.TxValidationCode
+	for _, r := range results {
+		codes = append(codes, r.Code)
+	}
+	return codes
+}
+
+func commitTx(t *testing.T, chain *MemoryFactoryChain, stub contract.IContractStub, f func() error) {
+	t.Helper()
+	if err := f(); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryBlockReadConflict(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putStates(t, chain, "counter")
+	block := chain.NewBlock()
+
+	for _, value := range []string{"1", "2"} {
+		stub := block.NewStub(testAddr)
+		commitTx(t, chain, stub, func() error {
+			if _, err := stub.GetState("counter"); err != nil {
+				return err
+			}
+			return stub.PutState("counter", []byte(value))
+		})
+	}
+	// a blind write does not conflict
+	stub := block.NewStub(testAddr)
+	commitTx(t, chain, stub, func() error { return stub.PutState("counter", []byte("3")) })
+
+	got := codesOf(block.Commit())
+	want := []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_VALID}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if v := stateOf(t, chain, defaultChaincode, "counter"); v != "3" {
+		t.Fatalf("counter = %q", v)
+	}
+}
+
+func TestMemoryBlockPhantomRead(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putStates(t, chain, "a", "c")
+	block := chain.NewBlock()
+
+	writer := block.NewStub(testAddr)
+	commitTx(t, chain, writer, func() error { return writer.PutState("b", []byte("x")) })
+	reader := block.NewStub(testAddr)
+	commitTx(t, chain, reader, func() error {
+		it, err := reader.GetStateByRange("a", "z")
+		if err != nil {
+			return err
+		}
+		keysOf(t, it)
+		return reader.PutState("sum", []byte("2"))
+	})
+
+	got := codesOf(block.Commit())
+	want := []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_PHANTOM_READ_CONFLICT}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if v := stateOf(t, chain, defaultChaincode, "sum"); v != "" {
+		t.Fatalf("write of the invalid tx applied: %q", v)
+	}
+}
+
+func TestMemoryBlockPaginatedRead(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putStates(t, chain, "a", "b", "c", "d")
+
+	readPage := func(block *MemoryBlock) {
+		reader := block.NewStub(testAddr)
+		commitTx(t, chain, reader, func() error {
+			it, _, err := reader.GetStateByRangeWithPagination("", "", 2, "")
+			if err != nil {
+				return err
+			}
+			keysOf(t, it)
+			return nil
+		})
+	}
+	write := func(block *MemoryBlock, key string) {
+		writer := block.NewStub(testAddr)
+		commitTx(t, chain, writer, func() error { return writer.PutState(key, []byte("x")) })
+	}
+
+	// a key written beyond the page was not read
+	block := chain.NewBlock()
+	write(block, "e")
+	readPage(block)
+	got := codesOf(block.Commit())
+	want := []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_VALID}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("write beyond the page: got %v, want %v", got, want)
+	}
+
+	block = chain.NewBlock()
+	write(block, "aa")
+	readPage(block)
+	got = codesOf(block.Commit())
+	want = []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_PHANTOM_READ_CONFLICT}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("write into the page: got %v, want %v", got, want)
+	}
+}
+
+func TestMemoryBlockSubmitCopiesTx(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxIDs(SequentialTxIDs("tx")))
+	block := chain.NewBlock()
+	stub := block.NewStub(testAddr)
+
+	commitTx(t, chain, stub, func() error { return stub.PutState("a", []byte("x")) })
+	// the stub goes on with the next transaction, which is discarded
+	if err := stub.PutState("b", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	chain.Rollback(stub)
+	commitTx(t, chain, stub, func() error { return stub.PutState("c", []byte("x")) })
+
+	results := block.Commit()
+	if len(results) != 2 || results[0].TxID != "tx1" || results[1].TxID != "tx3" {
+		t.Fatalf("got %+v %+v", results[0], results[len(results)-1])
+	}
+	for key, want := range map[string]string{"a": "x", "b": "", "c": "x"} {
+		if got := stateOf(t, chain, defaultChaincode, key); got != want {
+			t.Errorf("state %s = %q, want %q", key, got, want)
+		}
+	}
+
+	if err := chain.Commit(stub); err == nil {
+		t.Fatal("submitted to a committed block")
+	}
+}
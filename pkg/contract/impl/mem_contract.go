@@ -0,0 +1,45 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Deploy hosts cc under name on the channels, so that memory stubs can call
+// it with InvokeContract. Without channels it is deployed on the default one.
+func (m *MemoryFactoryChain) Deploy(name string, cc *FabricChaincode, channels ...string) {
+	if len(channels) == 0 {
+		channels = []string{defaultChannelID}
+	}
+	for _, channel := range channels {
+		m.contracts[namespaceKey(channel, name)] = cc
+	}
+}
+
+// InvokeContract runs the deployed chaincode in the same transaction. Like
+// Fabric, a chaincode on the same channel writes into the transaction while
+// one on another channel may only read. The writes of a call which fails are
+// discarded.
+func (m *memoryStub) InvokeContract(contractName string, args [][]byte, channel string) ([]byte, error) {
+	if channel == "" {
+		channel = m.channel
+	}
+	cc, ok := m.factory.contracts[namespaceKey(channel, contractName)]
+	if !ok {
+		return nil, fmt.Errorf("chaincode %s not found on channel %s", contractName, channel)
+	}
+
+	child := *m
+	child.tx = newMemoryTx()
+	child.args = args
+	child.channel = channel
+	child.chaincode = contractName
+	child.parent = m
+	child.readOnly = m.readOnly || channel != m.channel
+
+	resp := cc.invoke(&child)
+	if resp.Status != 200 {
+		return nil, errors.New(resp.Message)
+	}
+	return resp.Payload, nil
+}
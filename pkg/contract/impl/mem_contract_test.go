@@ -0,0 +1,82 @@
+package impl
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"hello/pkg/contract"
+)
+
+type Counter struct{}
+
+func (c *Counter) Inc(stub contract.IContractStub, key string) (int, error) {
+	v, err := stub.GetState(key)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(string(v))
+	n++
+	return n, stub.PutState(key, []byte(strconv.Itoa(n)))
+}
+
+type Caller struct{}
+
+func (c *Caller) Call(stub contract.IContractStub, name, method, channel string) (string, error) {
+	args, err := makeArgs(method, "n")
+	if err != nil {
+		return "", err
+	}
+	payload, err := stub.InvokeContract(name, args, channel)
+	return string(payload), err
+}
+
+func deployCounter(chain *MemoryFactoryChain, channels ...string) {
+	counter := NewFabricChaincode()
+	counter.Register(&Counter{})
+	chain.Deploy("counter", counter, channels...)
+
+	caller := NewFabricChaincode()
+	caller.Register(&Caller{})
+	chain.Deploy(defaultChaincode, caller)
+}
+
+func stateOf(t *testing.T, chain *MemoryFactoryChain, chaincode, key string) string {
+	t.Helper()
+	v, err := chain.NewStub(testAddr, WithChaincode(chaincode)).GetState(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(v)
+}
+
+func TestMemoryStubInvokeContract(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	deployCounter(chain)
+
+	resp := chain.Invoke(chain.NewStub(testAddr), "Caller.Call", "counter", "Counter.Inc", "")
+	if resp.Status != 200 || string(resp.Payload) != `"1"` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+	if got := stateOf(t, chain, "counter", "n"); got != "1" {
+		t.Fatalf("callee state n = %q", got)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "n"); got != "" {
+		t.Fatalf("caller state n = %q", got)
+	}
+}
+
+func TestMemoryStubInvokeContractAcrossChannels(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	deployCounter(chain, defaultChannelID, "other")
+
+	resp := chain.Invoke(chain.NewStub(testAddr), "Caller.Call", "counter", "Counter.Inc", "other")
+	if resp.Status == 200 || !strings.Contains(resp.Message, "can not write") {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+
+	resp = chain.Invoke(chain.NewStub(testAddr), "Caller.Call", "missing", "Counter.Inc", "")
+	if resp.Status == 200 || !strings.Contains(resp.Message, "not found") {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+}
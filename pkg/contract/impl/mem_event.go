@@ -0,0 +1,81 @@
+package impl
+
+// ChaincodeEvent is an event committed on the memory chain.
+type ChaincodeEvent struct {
+	BlockNum    uint64 `json:"block"`
+	TxID        string `json:"txId"`
+	ChaincodeID string `json:"chaincode"`
+	EventName   string `json:"name"`
+	Payload     []byte `json:"payload,omitempty"`
+}
+
+// EventFilter selects the events delivered to a subscription. Empty fields
+// match every event.
+type EventFilter struct {
+	ChaincodeID string
+	Names       []string
+	FromBlock   uint64
+}
+
+func (f *EventFilter) match(e *ChaincodeEvent) bool {
+	if e.BlockNum < f.FromBlock {
+		return false
+	}
+	if f.ChaincodeID != "" && f.ChaincodeID != e.ChaincodeID {
+		return false
+	}
+	if len(f.Names) == 0 {
+		return true
+	}
+	for _, name := range f.Names {
+		if name == e.EventName {
+			return true
+		}
+	}
+	return false
+}
+
+type memorySubscription struct {
+	filter   EventFilter
+	listener func(e *ChaincodeEvent)
+}
+
+// Events returns the committed events matching filter in commit order.
+func (m *MemoryFactoryChain) Events(filter EventFilter) []*ChaincodeEvent {
+	var events []*ChaincodeEvent
+	for _, e := range m.events {
+		if filter.match(e) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Subscribe calls listener with the committed events matching filter, first
+// the ones already committed from filter.FromBlock on and then each new one
+// as its transaction commits. Call cancel to stop the subscription.
+func (m *MemoryFactoryChain) Subscribe(filter EventFilter, listener func(e *ChaincodeEvent)) (cancel func()) {
+	for _, e := range m.Events(filter) {
+		listener(e)
+	}
+
+	sub := &memorySubscription{filter: filter, listener: listener}
+	m.subscriptions = append(m.subscriptions, sub)
+	return func() {
+		for i, s := range m.subscriptions {
+			if s == sub {
+				m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (m *MemoryFactoryChain) emit(e *ChaincodeEvent) {
+	m.events = append(m.events, e)
+	for _, sub := range append([]*memorySubscription(nil), m.subscriptions...) {
+		if sub.filter.match(e) {
+			sub.listener(e)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func emitEvents(t *testing.T, chain *MemoryFactoryChain, names ...string) {
+	t.Helper()
+	stub := chain.NewStub(testAddr)
+	for _, name := range names {
+		if err := stub.SetEvent(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func eventNames(events []*ChaincodeEvent) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.EventName)
+	}
+	return names
+}
+
+func TestMemoryChainEventLog(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation(), WithTxIDs(SequentialTxIDs("tx")))
+	// a transaction keeps its last event only
+	emitEvents(t, chain, "first", "created")
+	emitEvents(t, chain, "updated")
+	emitEvents(t, chain)
+
+	events := chain.Events(EventFilter{})
+	want := []*ChaincodeEvent{
+		{BlockNum: 1, TxID: "tx1", ChaincodeID: defaultChaincode, EventName: "created", Payload: []byte("created")},
+		{BlockNum: 2, TxID: "tx3", ChaincodeID: defaultChaincode, EventName: "updated", Payload: []byte("updated")},
+	}
+	if !reflect.DeepEqual(events, want) {
+		for _, e := range events {
+			t.Logf("%+v", *e)
+		}
+		t.Fatal("unexpected events")
+	}
+
+	if names := eventNames(chain.Events(EventFilter{FromBlock: 2})); !reflect.DeepEqual(names, []string{"updated"}) {
+		t.Fatalf("from block 2: got %q", names)
+	}
+	if names := eventNames(chain.Events(EventFilter{ChaincodeID: "other"})); names != nil {
+		t.Fatalf("other chaincode: got %q", names)
+	}
+
+	if err := chain.NewStub(testAddr).SetEvent("", nil); err == nil {
+		t.Fatal("event without name accepted")
+	}
+}
+
+func TestMemoryChainEventOnCommit(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxIDs(SequentialTxIDs("tx")))
+	stub := chain.NewStub(testAddr)
+	if err := stub.SetEvent("first", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.PutState("k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.SetEvent("created", nil); err != nil {
+		t.Fatal(err)
+	}
+	if events := chain.Events(EventFilter{}); len(events) != 0 {
+		t.Fatalf("emitted before commit: %q", eventNames(events))
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	events := chain.Events(EventFilter{})
+	if len(events) != 1 || events[0].EventName != "created" || events[0].TxID != "tx1" || events[0].BlockNum != 2 {
+		t.Fatalf("got %q", eventNames(events))
+	}
+}
+
+func TestMemoryChainSubscribe(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation())
+	emitEvents(t, chain, "created")
+	emitEvents(t, chain, "deleted")
+
+	var got []string
+	cancel := chain.Subscribe(EventFilter{Names: []string{"created", "updated"}}, func(e *ChaincodeEvent) {
+		got = append(got, e.EventName)
+	})
+	emitEvents(t, chain, "updated")
+	emitEvents(t, chain, "deleted")
+	cancel()
+	emitEvents(t, chain, "created")
+
+	if want := []string{"created", "updated"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestMemoryChainEventsOfFailedTx(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation())
+	stub := chain.NewStub(testAddr)
+	if err := stub.SetEvent("lost", nil); err != nil {
+		t.Fatal(err)
+	}
+	chain.Rollback(stub)
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if events := chain.Events(EventFilter{}); len(events) != 0 {
+		t.Fatalf("got %q", eventNames(events))
+	}
+}
@@ -0,0 +1,583 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hello/pkg/contract/identity"
+
+	"hello/pkg/contract"
+)
+
+type memoryStub struct {
+	address   string
+	mspID     string
+	attrs     map[string]string
+	creator   []byte // serialized identity, overrides address, mspID and attrs
+	transient map[string][]byte
+	args      [][]byte
+	channel   string
+	chaincode string
+	parent    *memoryStub // set on the stub of a called chaincode
+	readOnly  bool
+	block     *MemoryBlock // set in block mode
+	simulate  bool         // set while the RPC handler runs, see buffered
+	tx        *memoryTx
+	factory   *MemoryFactoryChain
+	txID      string
+	t         time.Time
+}
+
+func (m *memoryStub) GetArgs() [][]byte {
+	return m.args
+}
+
+func (m *memoryStub) GetTxID() string {
+	return m.txID
+}
+
+func (m *memoryStub) GetChannelID() string {
+	return m.channel
+}
+
+// ns returns the world state of the chaincode the stub runs for.
+func (m *memoryStub) ns() *memoryNamespace {
+	return m.factory.namespace(namespaceKey(m.channel, m.chaincode))
+}
+
+func (m *memoryStub) checkWritable() error {
+	if m.readOnly {
+		return fmt.Errorf("chaincode %s is invoked across channels and can not write", m.chaincode)
+	}
+	return nil
+}
+
+func (m *memoryStub) GetAddress() (string, error) {
+	if m.creator != nil {
+		addr, err := identity.IntoAddress(m.creator)
+		if err != nil {
+			return "", err
+		}
+		return addr.String(), nil
+	}
+	addr, err := identity.AddressFromHexString(m.address)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// GetClientIdentity returns the identity set up by the stub options. The
+// memory stub has no certificate unless it was given a creator.
+func (m *memoryStub) GetClientIdentity() (*identity.ClientIdentity, error) {
+	if m.creator != nil {
+		return identity.NewClientIdentity(m.creator)
+	}
+	addr, err := identity.AddressFromHexString(m.address)
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{}
+	for k, v := range m.attrs {
+		attrs[k] = v
+	}
+	return &identity.ClientIdentity{MSPID: m.mspID, Address: addr, Attrs: attrs}, nil
+}
+
+func (m *memoryStub) GetTransient() (map[string][]byte, error) {
+	return m.transient, nil
+}
+
+// GetState reads the committed state only. When the stub simulates the
+// transaction, its writes are not visible before it commits, like Fabric.
+func (m *memoryStub) GetState(key string) ([]byte, error) {
+	m.read(key)
+	v := m.ns().states[key]
+	return v, nil
+}
+
+func (m *memoryStub) PutState(key string, value []byte) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	m.updates().states[key] = &memoryWrite{value: value}
+	m.flush()
+	return nil
+}
+
+func (m *memoryStub) DelState(key string) ([]byte, error) {
+	if err := m.checkWritable(); err != nil {
+		return nil, err
+	}
+	v, _ := m.GetState(key)
+	m.updates().states[key] = newMemoryWrite(nil)
+	m.flush()
+	return v, nil
+}
+
+func (m *memoryStub) GetStateValidationParameter(key string) ([]byte, error) {
+	m.read(key)
+	return m.ns().validations[key], nil
+}
+
+func (m *memoryStub) SetStateValidationParameter(key string, ep []byte) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	m.updates().validations[key] = newMemoryWrite(ep)
+	m.flush()
+	return nil
+}
+
+func (m *memoryStub) GetStateByRange(startKey, endKey string) (contract.IStateIterator, error) {
+	filter := rangeFilter(startKey, endKey)
+	return m.rangeQuery(filter, m.scan(filter)), nil
+}
+
+func (m *memoryStub) GetStateByPartialCompositeKey(objectType string, keys []string) (contract.IStateIterator, error) {
+	prefix, err := m.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	filter := prefixFilter(prefix)
+	return m.rangeQuery(filter, m.scan(filter)), nil
+}
+
+func (m *memoryStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	if bookmark != "" {
+		startKey = bookmark
+	}
+	iter, err := m.GetStateByRange(startKey, endKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return paginate(iter.(*memoryStateIterator), pageSize)
+}
+
+func (m *memoryStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	prefix, err := m.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := prefixFilter(prefix)
+	if bookmark != "" {
+		inPrefix := filter
+		filter = func(k string) bool {
+			return k >= bookmark && inPrefix(k)
+		}
+	}
+	return paginate(m.rangeQuery(filter, m.scan(filter)), pageSize)
+}
+
+// GetQueryResult evaluates a CouchDB Mango query over the JSON states.
+func (m *memoryStub) GetQueryResult(query string) (contract.IStateIterator, error) {
+	q, err := parseMangoQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	kvs, err := q.execute(m.scan(func(string) bool { return true }))
+	if err != nil {
+		return nil, err
+	}
+	return &memoryStateIterator{kvs: kvs}, nil
+}
+
+// GetQueryResultWithPagination pages over the result of a Mango query. As
+// with CouchDB the page size replaces the query limit and the bookmark is
+// opaque to the caller. It holds the position of the last document returned,
+// so the next page starts after it.
+func (m *memoryStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	q, err := parseMangoQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs, err := q.match(m.scan(func(string) bool { return true }))
+	if err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		docs, err = q.after(docs, bookmark)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	meta := &contract.QueryMetadata{}
+	if len(docs) > int(pageSize) {
+		docs = docs[:pageSize]
+		meta.Bookmark = q.bookmark(docs[len(docs)-1])
+	}
+	meta.FetchedRecordsCount = int32(len(docs))
+	kvs, err := q.project(docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &memoryStateIterator{kvs: kvs}, meta, nil
+}
+
+// GetHistoryForKey returns the recorded modifications of key, newest first
+// like the Fabric peer.
+func (m *memoryStub) GetHistoryForKey(key string) (contract.IHistoryIterator, error) {
+	history := m.ns().history[key]
+	mods := make([]*contract.KeyModification, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		mods = append(mods, history[i])
+	}
+	return &memoryHistoryIterator{mods: mods}, nil
+}
+
+// scan returns the states matched by filter in lexical key order.
+func (m *memoryStub) scan(filter func(k string) bool) []*contract.StateKV {
+	return scanStates(m.ns().states, filter)
+}
+
+func scanStates(states map[string][]byte, filter func(k string) bool) []*contract.StateKV {
+	var keys []string
+	for k := range states {
+		if filter(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*contract.StateKV, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, &contract.StateKV{Key: k, Value: states[k]})
+	}
+	return kvs
+}
+
+// compositeKeyNamespace prefixes the composite keys of Fabric, which are
+// never returned by range queries.
+const compositeKeyNamespace = "\x00"
+
+func rangeFilter(startKey, endKey string) func(k string) bool {
+	return func(k string) bool {
+		if strings.HasPrefix(k, compositeKeyNamespace) {
+			return false
+		}
+		return k >= startKey && (endKey == "" || k < endKey)
+	}
+}
+
+func prefixFilter(prefix string) func(k string) bool {
+	return func(k string) bool {
+		if strings.HasPrefix(k, compositeKeyNamespace) {
+			return false
+		}
+		return k == prefix || strings.HasPrefix(k, prefix+"/")
+	}
+}
+
+func (m *memoryStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return contract.CreateKey(objectType, attributes)
+}
+
+func (m *memoryStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return contract.SplitKey(compositeKey)
+}
+
+func (m *memoryStub) GetTxTimestamp() (time.Time, error) {
+	return m.t, nil
+}
+
+// SetEvent keeps the event of the outer-most chaincode only, like Fabric. A
+// transaction has one event, the last one set, which is emitted when the
+// transaction commits, also if its writes were applied at once.
+func (m *memoryStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+	if m.parent != nil {
+		return nil
+	}
+	m.tx.event = &memoryEvent{name: name, payload: payload}
+	return nil
+}
+
+// AddEvent drops the events of a called chaincode like SetEvent.
+func (m *memoryStub) AddEvent(name string, payload []byte) error {
+	e, err := contract.NewEvent(name, payload)
+	if err != nil {
+		return err
+	}
+	if m.parent != nil {
+		return nil
+	}
+	m.tx.events = append(m.tx.events, e)
+	return nil
+}
+
+func (m *memoryStub) takeEvents() []*contract.Event {
+	events := m.tx.events
+	m.tx.events = nil
+	return events
+}
+
+func (m *memoryStub) lastEvent() (string, []byte, bool) {
+	if m.tx.event == nil {
+		return "", nil, false
+	}
+	return m.tx.event.name, m.tx.event.payload, true
+}
+
+// GetOriginStub returns the memory stub itself, there is no peer stub
+// beneath it.
+func (m *memoryStub) GetOriginStub() interface{} {
+	return m
+}
+
+type memoryStateIterator struct {
+	kvs         []*contract.StateKV
+	idx         int
+	closed      bool
+	onNext      func(kv *contract.StateKV)
+	onExhausted func()
+}
+
+func (it *memoryStateIterator) HasNext() bool {
+	if it.closed {
+		return false
+	}
+	if it.idx < len(it.kvs) {
+		return true
+	}
+	if it.onExhausted != nil {
+		it.onExhausted()
+	}
+	return false
+}
+
+func (it *memoryStateIterator) Next() (*contract.StateKV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no such key")
+	}
+	kv := it.kvs[it.idx]
+	it.idx++
+	if it.onNext != nil {
+		it.onNext(kv)
+	}
+	return kv, nil
+}
+
+func (it *memoryStateIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+// paginate cuts the first pageSize entries off it. Like the Fabric peer, the
+// bookmark is the key of the first entry of the next page.
+func paginate(it *memoryStateIterator, pageSize int32) (contract.IStateIterator, *contract.QueryMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	meta := &contract.QueryMetadata{}
+	if len(it.kvs) > int(pageSize) {
+		meta.Bookmark = it.kvs[pageSize].Key
+		it.kvs = it.kvs[:pageSize]
+		// the query read up to the last key of the page, not to the end
+		// of the range
+		it.onExhausted = nil
+	}
+	meta.FetchedRecordsCount = int32(len(it.kvs))
+	return it, meta, nil
+}
+
+type memoryHistoryIterator struct {
+	mods   []*contract.KeyModification
+	idx    int
+	closed bool
+}
+
+func (it *memoryHistoryIterator) HasNext() bool {
+	return !it.closed && it.idx < len(it.mods)
+}
+
+func (it *memoryHistoryIterator) Next() (*contract.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no such key")
+	}
+	mod := it.mods[it.idx]
+	it.idx++
+	return mod, nil
+}
+
+func (it *memoryHistoryIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+type MemoryFactoryChain struct {
+	simulateTx    bool                        // see WithTxSimulation
+	namespaces    map[string]*memoryNamespace // channel/chaincode -> world state
+	contracts     map[string]*FabricChaincode // channel/chaincode -> deployed chaincode
+	collections   map[string]map[string]bool  // collection -> member MSP IDs
+	events        []*ChaincodeEvent           // in commit order
+	subscriptions []*memorySubscription
+	height        uint64 // number of committed blocks
+	clock         func() time.Time
+	txIDs         func() string
+}
+
+func NewMemoryFactoryChain(opts ...ChainOption) *MemoryFactoryChain {
+	chain := &MemoryFactoryChain{
+		namespaces:  map[string]*memoryNamespace{},
+		contracts:   map[string]*FabricChaincode{},
+		collections: map[string]map[string]bool{},
+		clock:       time.Now,
+		txIDs:       defaultTxIDs(),
+	}
+	for _, opt := range opts {
+		opt(chain)
+	}
+	return chain
+}
+
+// StubOption customizes a stub created by MemoryFactoryChain.NewStub.
+type StubOption func(stub *memoryStub)
+
+// WithMSPID sets the MSP ID of the client organization. It also decides the
+// private data collections the stub can read.
+func WithMSPID(mspID string) StubOption {
+	return func(stub *memoryStub) {
+		stub.mspID = mspID
+	}
+}
+
+// WithAttributes sets the certificate attributes of the client identity.
+func WithAttributes(attrs map[string]string) StubOption {
+	return func(stub *memoryStub) {
+		stub.attrs = attrs
+	}
+}
+
+// WithChannel sets the channel the stub runs on.
+func WithChannel(channel string) StubOption {
+	return func(stub *memoryStub) {
+		stub.channel = channel
+	}
+}
+
+// WithChaincode sets the chaincode whose world state the stub works on.
+func WithChaincode(name string) StubOption {
+	return func(stub *memoryStub) {
+		stub.chaincode = name
+	}
+}
+
+// WithCreator sets the serialized identity which submits the transaction, as
+// returned by GetCreator of the Fabric stub. The address, MSP ID and
+// attributes are then taken from its certificate.
+func WithCreator(creator []byte) StubOption {
+	return func(stub *memoryStub) {
+		stub.creator = creator
+	}
+}
+
+// WithArgs sets the arguments of the first transaction, e.g. for a stub which
+// is not used through MemoryFactoryChain.Invoke.
+func WithArgs(args ...[]byte) StubOption {
+	return func(stub *memoryStub) {
+		stub.args = args
+	}
+}
+
+// WithTxID sets the id of the first transaction instead of the chain's
+// generator.
+func WithTxID(txID string) StubOption {
+	return func(stub *memoryStub) {
+		stub.txID = txID
+	}
+}
+
+// WithTimestamp sets the timestamp of the first transaction instead of the
+// chain's clock.
+func WithTimestamp(t time.Time) StubOption {
+	return func(stub *memoryStub) {
+		stub.t = t
+	}
+}
+
+// WithTransient sets the transient map of the proposal.
+func WithTransient(transient map[string][]byte) StubOption {
+	return func(stub *memoryStub) {
+		stub.transient = transient
+	}
+}
+
+// NewStub creates a stub for the client with the address addr. A stub
+// simulates one transaction at a time; after it is committed or discarded
+// the stub continues with a new tx id and timestamp.
+func (m *MemoryFactoryChain) NewStub(addr string, opts ...StubOption) contract.IContractStub {
+	stub := &memoryStub{
+		address:   addr,
+		mspID:     defaultMSPID,
+		channel:   defaultChannelID,
+		chaincode: defaultChaincode,
+		tx:        newMemoryTx(),
+		factory:   m,
+		txID:      m.txIDs(),
+		t:         m.clock(),
+	}
+	for _, opt := range opts {
+		opt(stub)
+	}
+	return stub
+}
+
+func (m *MemoryFactoryChain) Debug(prefix ...string) {
+	fmt.Println("------------STATES-------------")
+	m.debugNamespace(m.namespace(namespaceKey(defaultChannelID, defaultChaincode)), prefix...)
+
+	var names []string
+	for name := range m.namespaces {
+		if name != namespaceKey(defaultChannelID, defaultChaincode) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("------------STATES %s-------------\n", name)
+		m.debugNamespace(m.namespaces[name], prefix...)
+	}
+
+	fmt.Println("------------EVENTS-------------")
+	for _, e := range m.events {
+		fmt.Printf("%d %s %s %s -> %s\n", e.BlockNum, e.TxID, e.ChaincodeID, e.EventName, string(e.Payload))
+	}
+}
+
+func (m *MemoryFactoryChain) debugNamespace(ns *memoryNamespace, prefix ...string) {
+	var keys []string
+	for k := range ns.states {
+		if len(prefix) == 0 {
+			keys = append(keys, k)
+		} else {
+			if strings.HasPrefix(k, prefix[0]) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s -> %s\n", k, string(ns.states[k]))
+	}
+
+	var names []string
+	for name := range ns.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("------------PRIVATE %s-------------\n", name)
+		for _, kv := range scanStates(ns.collections[name].states, func(string) bool { return true }) {
+			fmt.Printf("%s -> %s\n", kv.Key, string(kv.Value))
+		}
+	}
+}
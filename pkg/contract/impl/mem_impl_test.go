@@ -0,0 +1,152 @@
+package impl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hello/pkg/contract"
+)
+
+var testAddr = strings.Repeat("0a", 20)
+
+func keysOf(t *testing.T, it contract.IStateIterator) []string {
+	t.Helper()
+	defer it.Close()
+	var keys []string
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, kv.Key)
+	}
+	return keys
+}
+
+func putStates(t *testing.T, chain *MemoryFactoryChain, keys ...string) {
+	t.Helper()
+	stub := chain.NewStub(testAddr)
+	for _, k := range keys {
+		if err := stub.PutState(k, []byte("v-"+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryStubGetStateByRange(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	composite, err := contract.CreateCompositeKey("owner", []string{"b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putStates(t, chain, "c", "a", "b", composite)
+	stub := chain.NewStub(testAddr)
+
+	cases := []struct {
+		start, end string
+		want       []string
+	}{
+		{"", "", []string{"a", "b", "c"}},
+		{"b", "", []string{"b", "c"}},
+		{"a", "c", []string{"a", "b"}},
+		{"d", "", nil},
+	}
+	for _, c := range cases {
+		it, err := stub.GetStateByRange(c.start, c.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := keysOf(t, it); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("range [%q, %q): got %q, want %q", c.start, c.end, got, c.want)
+		}
+	}
+}
+
+func TestMemoryStateIteratorExhausted(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putStates(t, chain, "a")
+	it, err := chain.NewStub(testAddr).GetStateByRange("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := it.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if it.HasNext() {
+		t.Fatal("HasNext after the last key")
+	}
+	if _, err := it.Next(); err == nil {
+		t.Fatal("Next after the last key succeeded")
+	}
+}
+
+func TestMemoryStubGetHistoryForKey(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	chain := NewMemoryFactoryChain(WithClock(SteppingClock(start, time.Second)), WithTxIDs(SequentialTxIDs("tx")))
+	stub := chain.NewStub(testAddr)
+	for _, write := range []func() error{
+		func() error { return stub.PutState("k", []byte("v1")) },
+		func() error { return stub.PutState("other", []byte("x")) },
+		func() error { return stub.PutState("k", []byte("v2")) },
+		func() error { _, err := stub.DelState("k"); return err },
+	} {
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+		if err := chain.Commit(stub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it, err := stub.GetHistoryForKey("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var got []contract.KeyModification
+	for it.HasNext() {
+		mod, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, *mod)
+	}
+	want := []contract.KeyModification{
+		{TxID: "tx4", Timestamp: start.Add(3 * time.Second), IsDelete: true},
+		{TxID: "tx3", Timestamp: start.Add(2 * time.Second), Value: []byte("v2")},
+		{TxID: "tx1", Timestamp: start, Value: []byte("v1")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryStubGetOriginStub(t *testing.T) {
+	stub := NewMemoryFactoryChain().NewStub(testAddr)
+	if origin := stub.GetOriginStub(); origin != stub {
+		t.Fatalf("got %v", origin)
+	}
+}
+
+func TestMemoryStubGetClientIdentity(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	stub := chain.NewStub(testAddr, WithMSPID("Org1MSP"), WithAttributes(map[string]string{"role": "admin"}))
+	ci, err := stub.GetClientIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.MSPID != "Org1MSP" || !strings.EqualFold(ci.Address.String(), testAddr) {
+		t.Fatalf("got %s %s", ci.MSPID, ci.Address)
+	}
+	if err := ci.AssertAttributeValue("role", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.NewStub("not an address").GetClientIdentity(); err == nil {
+		t.Fatal("invalid address accepted")
+	}
+}
@@ -0,0 +1,81 @@
+package impl
+
+import (
+	"strings"
+
+	"hello/pkg/contract"
+)
+
+const (
+	defaultChannelID = "mem-channel"
+	defaultChaincode = "mem-cc"
+)
+
+// memoryVersion is the height of the transaction which last wrote a key.
+type memoryVersion struct {
+	BlockNum uint64 `json:"block"`
+	TxNum    uint64 `json:"tx"`
+}
+
+func sameVersion(a, b *memoryVersion) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// memoryNamespace is the world state of one chaincode on one channel.
+type memoryNamespace struct {
+	states      map[string][]byte
+	versions    map[string]*memoryVersion
+	validations map[string][]byte
+	history     map[string][]*contract.KeyModification
+	collections map[string]*memoryCollection
+}
+
+func newMemoryNamespace() *memoryNamespace {
+	return &memoryNamespace{
+		states:      map[string][]byte{},
+		versions:    map[string]*memoryVersion{},
+		validations: map[string][]byte{},
+		history:     map[string][]*contract.KeyModification{},
+		collections: map[string]*memoryCollection{},
+	}
+}
+
+type memoryCollection struct {
+	states      map[string][]byte
+	validations map[string][]byte
+}
+
+func (ns *memoryNamespace) collection(name string) *memoryCollection {
+	c, ok := ns.collections[name]
+	if !ok {
+		c = &memoryCollection{states: map[string][]byte{}, validations: map[string][]byte{}}
+		ns.collections[name] = c
+	}
+	return c
+}
+
+func namespaceKey(channel, chaincode string) string {
+	return channel + "/" + chaincode
+}
+
+// splitNamespaceKey returns the channel and chaincode of a namespace key.
+// Channel names can not contain a slash.
+func splitNamespaceKey(key string) (channel, chaincode string) {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) < 2 {
+		return "", key
+	}
+	return parts[0], parts[1]
+}
+
+func (m *MemoryFactoryChain) namespace(key string) *memoryNamespace {
+	ns, ok := m.namespaces[key]
+	if !ok {
+		ns = newMemoryNamespace()
+		m.namespaces[key] = ns
+	}
+	return ns
+}
@@ -0,0 +1,108 @@
+package impl
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"hello/pkg/utils"
+)
+
+// ChainOption customizes a MemoryFactoryChain.
+type ChainOption func(chain *MemoryFactoryChain)
+
+// WithTxSimulation makes the stubs used directly simulate transactions like
+// a peer: the writes are buffered in a write set, invisible to reads, and
+// applied when Commit is called. Without it every write of such a stub is
+// applied at once, in a block of its own. Invocations through the RPC
+// handler, e.g. with Invoke, and called chaincodes always simulate their
+// transaction.
+func WithTxSimulation() ChainOption {
+	return func(chain *MemoryFactoryChain) {
+		chain.simulateTx = true
+	}
+}
+
+// WithClock sets the clock which stamps the timestamp of every transaction.
+// The default is time.Now.
+func WithClock(clock func() time.Time) ChainOption {
+	return func(chain *MemoryFactoryChain) {
+		chain.clock = clock
+	}
+}
+
+// WithTxIDs sets the generator of transaction ids. The default hashes the
+// time and a sequence number.
+func WithTxIDs(txIDs func() string) ChainOption {
+	return func(chain *MemoryFactoryChain) {
+		chain.txIDs = txIDs
+	}
+}
+
+// FixedClock returns a clock which always reads t.
+func FixedClock(t time.Time) func() time.Time {
+	return func() time.Time {
+		return t
+	}
+}
+
+// SteppingClock returns a clock which reads start first and step later each
+// time it is read, i.e. per transaction.
+func SteppingClock(start time.Time, step time.Duration) func() time.Time {
+	var (
+		mu   sync.Mutex
+		next = start
+	)
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		t := next
+		next = next.Add(step)
+		return t
+	}
+}
+
+// SequentialTxIDs returns a generator of the tx ids prefix1, prefix2, ...
+func SequentialTxIDs(prefix string) func() string {
+	var (
+		mu  sync.Mutex
+		seq uint64
+	)
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		seq++
+		return prefix + strconv.FormatUint(seq, 10)
+	}
+}
+
+// SeededTxIDs returns a generator of random looking tx ids in the format of
+// Fabric, which are the same for the same seed.
+func SeededTxIDs(seed int64) func() string {
+	var (
+		mu  sync.Mutex
+		rnd = rand.New(rand.NewSource(seed))
+	)
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		buf := make([]byte, 32)
+		rnd.Read(buf)
+		return fmt.Sprintf("%x", buf)
+	}
+}
+
+func defaultTxIDs() func() string {
+	var (
+		mu  sync.Mutex
+		seq uint64
+	)
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		seq++
+		return string(utils.Sha256Encode([]byte(time.Now().String() + strconv.FormatUint(seq, 10))))
+	}
+}
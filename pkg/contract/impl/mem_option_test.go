@@ -0,0 +1,78 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClocks(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixed := FixedClock(start)
+	if !fixed().Equal(start) || !fixed().Equal(start) {
+		t.Fatal("fixed clock moved")
+	}
+	stepping := SteppingClock(start, time.Minute)
+	for i := 0; i < 3; i++ {
+		if got, want := stepping(), start.Add(time.Duration(i)*time.Minute); !got.Equal(want) {
+			t.Fatalf("read %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestTxIDs(t *testing.T) {
+	seq := SequentialTxIDs("tx")
+	if a, b := seq(), seq(); a != "tx1" || b != "tx2" {
+		t.Fatalf("got %s %s", a, b)
+	}
+
+	a, b := SeededTxIDs(7), SeededTxIDs(7)
+	for i := 0; i < 3; i++ {
+		x, y := a(), b()
+		if x != y || len(x) != 64 {
+			t.Fatalf("got %s and %s for the same seed", x, y)
+		}
+	}
+	if SeededTxIDs(7)() == SeededTxIDs(8)() {
+		t.Fatal("same tx id for different seeds")
+	}
+
+	ids := defaultTxIDs()
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := ids()
+		if seen[id] {
+			t.Fatalf("duplicate tx id %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestNewStubOptions(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	chain := NewMemoryFactoryChain(WithClock(SteppingClock(start, time.Second)), WithTxIDs(SequentialTxIDs("tx")))
+
+	stub := chain.NewStub(testAddr)
+	if stub.GetTxID() != "tx1" || stub.GetArgs() != nil || stub.GetChannelID() != defaultChannelID {
+		t.Fatalf("got %s %q %s", stub.GetTxID(), stub.GetArgs(), stub.GetChannelID())
+	}
+	if ts, _ := stub.GetTxTimestamp(); !ts.Equal(start) {
+		t.Fatalf("got timestamp %v", ts)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if ts, _ := stub.GetTxTimestamp(); stub.GetTxID() != "tx2" || !ts.Equal(start.Add(time.Second)) {
+		t.Fatalf("next tx: got %s at %v", stub.GetTxID(), ts)
+	}
+
+	at := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	args := [][]byte{[]byte("Svc.Method"), []byte("[]")}
+	stub = chain.NewStub(testAddr, WithTxID("mine"), WithTimestamp(at), WithArgs(args...), WithChannel("ch"))
+	if ts, _ := stub.GetTxTimestamp(); stub.GetTxID() != "mine" || !ts.Equal(at) {
+		t.Fatalf("got %s at %v", stub.GetTxID(), ts)
+	}
+	if !reflect.DeepEqual(stub.GetArgs(), args) || stub.GetChannelID() != "ch" {
+		t.Fatalf("got %q on %s", stub.GetArgs(), stub.GetChannelID())
+	}
+}
@@ -0,0 +1,137 @@
+package impl
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/identity"
+)
+
+const defaultMSPID = "mem-msp"
+
+// DefineCollection declares a private data collection whose data can be
+// read by stubs of the member organizations only. Every chaincode keeps its
+// own data in the collection.
+func (m *MemoryFactoryChain) DefineCollection(name string, memberMSPIDs ...string) {
+	members := map[string]bool{}
+	for _, id := range memberMSPIDs {
+		members[id] = true
+	}
+	m.collections[name] = members
+}
+
+func (m *memoryStub) collection(name string) (*memoryCollection, error) {
+	if _, ok := m.factory.collections[name]; !ok {
+		return nil, fmt.Errorf("collection %s could not be found", name)
+	}
+	return m.ns().collection(name), nil
+}
+
+// readableCollection returns the collection if the organization of the stub
+// is one of its members.
+func (m *memoryStub) readableCollection(name string) (*memoryCollection, error) {
+	c, err := m.collection(name)
+	if err != nil {
+		return nil, err
+	}
+	mspID := m.mspID
+	if m.creator != nil {
+		ci, err := identity.NewClientIdentity(m.creator)
+		if err != nil {
+			return nil, err
+		}
+		mspID = ci.MSPID
+	}
+	if !m.factory.collections[name][mspID] {
+		return nil, fmt.Errorf("tx creator does not have read access permission on privatedata in collectionName:%s", name)
+	}
+	return c, nil
+}
+
+func (m *memoryStub) GetPrivateData(collection, key string) ([]byte, error) {
+	c, err := m.readableCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+	return c.states[key], nil
+}
+
+// GetPrivateDataHash works for non members too, as the hashes are stored on
+// every peer of the channel.
+func (m *memoryStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	c, err := m.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := c.states[key]
+	if !ok {
+		return nil, nil
+	}
+	hash := sha256.Sum256(v)
+	return hash[:], nil
+}
+
+func (m *memoryStub) PutPrivateData(collection, key string, value []byte) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := m.collection(collection); err != nil {
+		return err
+	}
+	m.updates().collection(collection).states[key] = newMemoryWrite(value)
+	m.flush()
+	return nil
+}
+
+func (m *memoryStub) DelPrivateData(collection, key string) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := m.collection(collection); err != nil {
+		return err
+	}
+	m.updates().collection(collection).states[key] = newMemoryWrite(nil)
+	m.flush()
+	return nil
+}
+
+func (m *memoryStub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	c, err := m.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	return c.validations[key], nil
+}
+
+func (m *memoryStub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := m.collection(collection); err != nil {
+		return err
+	}
+	m.updates().collection(collection).validations[key] = newMemoryWrite(ep)
+	m.flush()
+	return nil
+}
+
+func (m *memoryStub) GetPrivateDataByRange(collection, startKey, endKey string) (contract.IStateIterator, error) {
+	c, err := m.readableCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryStateIterator{kvs: scanStates(c.states, rangeFilter(startKey, endKey))}, nil
+}
+
+func (m *memoryStub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (contract.IStateIterator, error) {
+	c, err := m.readableCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := m.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryStateIterator{kvs: scanStates(c.states, prefixFilter(prefix))}, nil
+}
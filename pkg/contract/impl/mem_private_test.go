@@ -0,0 +1,81 @@
+package impl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryStubPrivateData(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	chain.DefineCollection("secret", "Org1")
+	member := chain.NewStub(testAddr, WithMSPID("Org1"))
+	other := chain.NewStub(testAddr, WithMSPID("Org2"))
+
+	for _, k := range []string{"b", "a", "c"} {
+		if err := member.PutPrivateData("secret", k, []byte("v-"+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.Commit(member); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := member.GetPrivateData("secret", "a")
+	if err != nil || string(v) != "v-a" {
+		t.Fatalf("got %q, %v", v, err)
+	}
+	if _, err := other.GetPrivateData("secret", "a"); err == nil {
+		t.Fatal("non member read private data")
+	}
+	if _, err := other.GetPrivateDataByRange("secret", "", ""); err == nil {
+		t.Fatal("non member scanned private data")
+	}
+
+	hash := sha256.Sum256([]byte("v-a"))
+	got, err := other.GetPrivateDataHash("secret", "a")
+	if err != nil || !bytes.Equal(got, hash[:]) {
+		t.Fatalf("got hash %x, %v", got, err)
+	}
+	if got, err := other.GetPrivateDataHash("secret", "missing"); err != nil || got != nil {
+		t.Fatalf("got hash %x, %v for a missing key", got, err)
+	}
+
+	it, err := member.GetPrivateDataByRange("secret", "b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := keysOf(t, it); !reflect.DeepEqual(keys, []string{"b", "c"}) {
+		t.Fatalf("got %q", keys)
+	}
+
+	// the world state is separate
+	if v, _ := member.GetState("a"); v != nil {
+		t.Fatalf("private data leaked to the world state: %q", v)
+	}
+	if err := member.PutPrivateData("unknown", "a", []byte("x")); err == nil {
+		t.Fatal("wrote to an undefined collection")
+	}
+}
+
+func TestMemoryStubDelPrivateData(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	chain.DefineCollection("secret", defaultMSPID)
+	stub := chain.NewStub(testAddr)
+	if err := stub.PutPrivateData("secret", "a", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.DelPrivateData("secret", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := stub.GetPrivateData("secret", "a"); err != nil || v != nil {
+		t.Fatalf("got %q, %v after delete", v, err)
+	}
+}
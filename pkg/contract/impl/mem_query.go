@@ -0,0 +1,457 @@
+package impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"hello/pkg/contract"
+	"hello/pkg/utils"
+)
+
+// mangoQuery is the subset of the CouchDB Mango query syntax evaluated by the
+// memory chain.
+type mangoQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort"`
+	Fields   []string               `json:"fields"`
+	Limit    int                    `json:"limit"`
+	Skip     int                    `json:"skip"`
+
+	sorts   []*sortField
+	regexps map[string]*regexp.Regexp // compiled once per query
+}
+
+type sortField struct {
+	path []string
+	desc bool
+}
+
+type document struct {
+	kv  *contract.StateKV
+	doc map[string]interface{}
+}
+
+// queryBookmark locates the last document of a page in the order of the
+// query, so that the next page starts after it even if documents were
+// inserted or deleted meanwhile.
+type queryBookmark struct {
+	Key  string        `json:"key"`
+	Sort []interface{} `json:"sort,omitempty"`
+}
+
+func parseMangoQuery(query string) (*mangoQuery, error) {
+	q := &mangoQuery{}
+	if err := json.Unmarshal([]byte(query), q); err != nil {
+		return nil, fmt.Errorf("invalid query: %s", err)
+	}
+	if q.Selector == nil {
+		return nil, fmt.Errorf("invalid query: selector is required")
+	}
+	sorts, err := q.sortFields()
+	if err != nil {
+		return nil, err
+	}
+	q.sorts = sorts
+	q.regexps = map[string]*regexp.Regexp{}
+	return q, nil
+}
+
+// execute runs the query over kvs, which must be in lexical key order.
+func (q *mangoQuery) execute(kvs []*contract.StateKV) ([]*contract.StateKV, error) {
+	docs, err := q.match(kvs)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Skip > 0 {
+		if q.Skip >= len(docs) {
+			docs = nil
+		} else {
+			docs = docs[q.Skip:]
+		}
+	}
+	if q.Limit > 0 && len(docs) > q.Limit {
+		docs = docs[:q.Limit]
+	}
+	return q.project(docs)
+}
+
+// match returns the documents selected by the query in its sort order, the
+// key order for equal sort fields. Values that are not JSON objects are
+// skipped like CouchDB attachments.
+func (q *mangoQuery) match(kvs []*contract.StateKV) ([]*document, error) {
+	var docs []*document
+	for _, kv := range kvs {
+		var doc map[string]interface{}
+		if json.Unmarshal(kv.Value, &doc) != nil || doc == nil {
+			continue
+		}
+		ok, err := q.matchSelector(doc, q.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			docs = append(docs, &document{kv: kv, doc: doc})
+		}
+	}
+
+	if len(q.sorts) > 0 {
+		sort.SliceStable(docs, func(i, j int) bool {
+			for _, s := range q.sorts {
+				a, _ := lookupField(docs[i].doc, s.path)
+				b, _ := lookupField(docs[j].doc, s.path)
+				c := compareJSON(a, b)
+				if c == 0 {
+					continue
+				}
+				if s.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	return docs, nil
+}
+
+func (q *mangoQuery) bookmark(d *document) string {
+	bm := &queryBookmark{Key: d.kv.Key}
+	for _, s := range q.sorts {
+		v, _ := lookupField(d.doc, s.path)
+		bm.Sort = append(bm.Sort, v)
+	}
+	buf, _ := json.Marshal(bm)
+	return utils.Base64Encode(buf)
+}
+
+// after returns the documents which come after the one of the bookmark.
+func (q *mangoQuery) after(docs []*document, bookmark string) ([]*document, error) {
+	bm := &queryBookmark{}
+	buf, err := utils.Base64Decode(bookmark)
+	if err != nil || json.Unmarshal(buf, bm) != nil || len(bm.Sort) != len(q.sorts) {
+		return nil, fmt.Errorf("invalid bookmark: %s", bookmark)
+	}
+	idx := sort.Search(len(docs), func(i int) bool {
+		return q.compareBookmark(docs[i], bm) > 0
+	})
+	return docs[idx:], nil
+}
+
+func (q *mangoQuery) compareBookmark(d *document, bm *queryBookmark) int {
+	for i, s := range q.sorts {
+		v, _ := lookupField(d.doc, s.path)
+		c := compareJSON(v, bm.Sort[i])
+		if s.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(d.kv.Key, bm.Key)
+}
+
+func (q *mangoQuery) project(docs []*document) ([]*contract.StateKV, error) {
+	result := make([]*contract.StateKV, 0, len(docs))
+	for _, d := range docs {
+		if len(q.Fields) == 0 {
+			result = append(result, d.kv)
+			continue
+		}
+		value, err := json.Marshal(project(d.doc, q.Fields))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &contract.StateKV{Key: d.kv.Key, Value: value})
+	}
+	return result, nil
+}
+
+func (q *mangoQuery) sortFields() ([]*sortField, error) {
+	var sorts []*sortField
+	for _, s := range q.Sort {
+		switch v := s.(type) {
+		case string:
+			sorts = append(sorts, &sortField{path: splitPath(v)})
+		case map[string]interface{}:
+			if len(v) != 1 {
+				return nil, fmt.Errorf("invalid sort: %v", v)
+			}
+			for field, dir := range v {
+				switch dir {
+				case "asc":
+					sorts = append(sorts, &sortField{path: splitPath(field)})
+				case "desc":
+					sorts = append(sorts, &sortField{path: splitPath(field), desc: true})
+				default:
+					return nil, fmt.Errorf("invalid sort direction: %v", dir)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid sort: %v", v)
+		}
+	}
+	return sorts, nil
+}
+
+func (q *mangoQuery) matchSelector(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, cond := range selector {
+		var (
+			ok  bool
+			err error
+		)
+		switch field {
+		case "$and":
+			ok, err = q.matchCombination(doc, cond, true)
+		case "$or":
+			ok, err = q.matchCombination(doc, cond, false)
+		case "$not":
+			sub, isMap := cond.(map[string]interface{})
+			if !isMap {
+				return false, fmt.Errorf("invalid $not operand: %v", cond)
+			}
+			ok, err = q.matchSelector(doc, sub)
+			ok = !ok
+		default:
+			value, exists := lookupField(doc, splitPath(field))
+			ok, err = q.matchField(value, exists, cond)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (q *mangoQuery) matchCombination(doc map[string]interface{}, cond interface{}, all bool) (bool, error) {
+	list, ok := cond.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("invalid combination operand: %v", cond)
+	}
+	for _, item := range list {
+		sub, ok := item.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("invalid combination operand: %v", item)
+		}
+		matched, err := q.matchSelector(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		if matched != all {
+			return matched, nil
+		}
+	}
+	return all, nil
+}
+
+func (q *mangoQuery) matchField(value interface{}, exists bool, cond interface{}) (bool, error) {
+	ops, ok := cond.(map[string]interface{})
+	if !ok {
+		return exists && compareJSON(value, cond) == 0, nil
+	}
+	if !isOperatorObject(ops) {
+		// a nested selector on a sub document
+		sub, isMap := value.(map[string]interface{})
+		if !exists || !isMap {
+			return false, nil
+		}
+		return q.matchSelector(sub, ops)
+	}
+
+	for op, arg := range ops {
+		var matched bool
+		switch op {
+		case "$eq":
+			matched = exists && compareJSON(value, arg) == 0
+		case "$ne":
+			matched = exists && compareJSON(value, arg) != 0
+		case "$gt":
+			matched = exists && sameKind(value, arg) && compareJSON(value, arg) > 0
+		case "$gte":
+			matched = exists && sameKind(value, arg) && compareJSON(value, arg) >= 0
+		case "$lt":
+			matched = exists && sameKind(value, arg) && compareJSON(value, arg) < 0
+		case "$lte":
+			matched = exists && sameKind(value, arg) && compareJSON(value, arg) <= 0
+		case "$in", "$nin":
+			list, ok := arg.([]interface{})
+			if !ok {
+				return false, fmt.Errorf("invalid %s operand: %v", op, arg)
+			}
+			for _, item := range list {
+				if exists && compareJSON(value, item) == 0 {
+					matched = true
+					break
+				}
+			}
+			if op == "$nin" {
+				matched = exists && !matched
+			}
+		case "$exists":
+			want, ok := arg.(bool)
+			if !ok {
+				return false, fmt.Errorf("invalid $exists operand: %v", arg)
+			}
+			matched = exists == want
+		case "$regex":
+			pattern, ok := arg.(string)
+			if !ok {
+				return false, fmt.Errorf("invalid $regex operand: %v", arg)
+			}
+			re, err := q.regexp(pattern)
+			if err != nil {
+				return false, err
+			}
+			str, isStr := value.(string)
+			matched = exists && isStr && re.MatchString(str)
+		case "$not":
+			m, err := q.matchField(value, exists, arg)
+			if err != nil {
+				return false, err
+			}
+			matched = !m
+		default:
+			return false, fmt.Errorf("unsupported operator: %s", op)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (q *mangoQuery) regexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := q.regexps[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $regex operand: %s", err)
+	}
+	q.regexps[pattern] = re
+	return re, nil
+}
+
+func isOperatorObject(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(field string) []string {
+	return strings.Split(field, ".")
+}
+
+func lookupField(doc map[string]interface{}, path []string) (interface{}, bool) {
+	var cur interface{} = doc
+	for _, p := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[p]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func project(doc map[string]interface{}, fields []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, field := range fields {
+		path := splitPath(field)
+		value, ok := lookupField(doc, path)
+		if !ok {
+			continue
+		}
+		cur := out
+		for _, p := range path[:len(path)-1] {
+			next, ok := cur[p].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				cur[p] = next
+			}
+			cur = next
+		}
+		cur[path[len(path)-1]] = value
+	}
+	return out
+}
+
+// jsonRank orders JSON types the way CouchDB collates them.
+func jsonRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func sameKind(a, b interface{}) bool {
+	return jsonRank(a) == jsonRank(b)
+}
+
+func compareJSON(a, b interface{}) int {
+	ra, rb := jsonRank(a), jsonRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case float64:
+		y := b.(float64)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareJSON(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]interface{}:
+		xb, _ := json.Marshal(x)
+		yb, _ := json.Marshal(b)
+		return strings.Compare(string(xb), string(yb))
+	}
+	return 0
+}
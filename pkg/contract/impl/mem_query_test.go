@@ -0,0 +1,141 @@
+package impl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func putDocs(t *testing.T, chain *MemoryFactoryChain, docs map[string]string) {
+	t.Helper()
+	stub := chain.NewStub(testAddr)
+	for k, v := range docs {
+		if err := stub.PutState(k, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var testDocs = map[string]string{
+	"a1": `{"type":"asset","owner":"bob","size":5,"color":"red"}`,
+	"a2": `{"type":"asset","owner":"alice","size":10}`,
+	"a3": `{"type":"asset","owner":"carol","size":15,"color":"blue"}`,
+	"o1": `{"type":"order","owner":"bob","size":1}`,
+	"x1": `not json`,
+}
+
+func TestMemoryStubGetQueryResult(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putDocs(t, chain, testDocs)
+	stub := chain.NewStub(testAddr)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{`{"selector":{"type":"asset"}}`, []string{"a1", "a2", "a3"}},
+		{`{"selector":{"size":{"$gt":5}}}`, []string{"a2", "a3"}},
+		{`{"selector":{"size":{"$gte":5,"$lt":15}}}`, []string{"a1", "a2"}},
+		{`{"selector":{"owner":{"$in":["bob","carol"]},"type":"asset"}}`, []string{"a1", "a3"}},
+		{`{"selector":{"$or":[{"owner":"alice"},{"type":"order"}]}}`, []string{"a2", "o1"}},
+		{`{"selector":{"$and":[{"type":"asset"},{"owner":{"$regex":"^c"}}]}}`, []string{"a3"}},
+		{`{"selector":{"color":{"$ne":"red"}}}`, []string{"a3"}},
+		{`{"selector":{"color":{"$nin":["blue"]}}}`, []string{"a1"}},
+		{`{"selector":{"color":{"$exists":false}}}`, []string{"a2", "o1"}},
+		{`{"selector":{"type":"asset"},"sort":[{"size":"desc"}],"limit":2}`, []string{"a3", "a2"}},
+		{`{"selector":{"type":"asset"},"sort":["owner"],"skip":1}`, []string{"a1", "a3"}},
+	}
+	for _, c := range cases {
+		it, err := stub.GetQueryResult(c.query)
+		if err != nil {
+			t.Fatalf("%s: %v", c.query, err)
+		}
+		if got := keysOf(t, it); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %q, want %q", c.query, got, c.want)
+		}
+	}
+
+	for _, query := range []string{
+		`{}`,
+		`{"selector":{"owner":{"$regex":"("}}}`,
+		`{"selector":{"owner":{"$foo":1}}}`,
+	} {
+		if _, err := stub.GetQueryResult(query); err == nil {
+			t.Errorf("%s: no error", query)
+		}
+	}
+}
+
+func TestMemoryStubGetQueryResultFields(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putDocs(t, chain, testDocs)
+	it, err := chain.NewStub(testAddr).GetQueryResult(`{"selector":{"owner":"alice"},"fields":["owner"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kv, err := it.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kv.Value) != `{"owner":"alice"}` {
+		t.Fatalf("got %s", kv.Value)
+	}
+}
+
+func queryPages(t *testing.T, chain *MemoryFactoryChain, query string, pageSize int32, between func()) [][]string {
+	t.Helper()
+	stub := chain.NewStub(testAddr)
+	var (
+		pages    [][]string
+		bookmark string
+	)
+	for {
+		it, meta, err := stub.GetQueryResultWithPagination(query, pageSize, bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := keysOf(t, it)
+		if int(meta.FetchedRecordsCount) != len(keys) {
+			t.Fatalf("fetched %d, got %d", meta.FetchedRecordsCount, len(keys))
+		}
+		pages = append(pages, keys)
+		if meta.Bookmark == "" {
+			return pages
+		}
+		bookmark = meta.Bookmark
+		if between != nil {
+			between()
+			between = nil
+		}
+	}
+}
+
+func TestMemoryStubGetQueryResultWithPagination(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	putDocs(t, chain, testDocs)
+
+	got := queryPages(t, chain, `{"selector":{"type":"asset"}}`, 2, func() {
+		// a key inserted before the bookmark does not shift the next page
+		putDocs(t, chain, map[string]string{"a0": `{"type":"asset","size":20}`})
+	})
+	want := [][]string{{"a1", "a2"}, {"a3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	got = queryPages(t, chain, `{"selector":{"type":"asset"},"sort":[{"size":"desc"}]}`, 3, nil)
+	want = [][]string{{"a0", "a3", "a2"}, {"a1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	stub := chain.NewStub(testAddr)
+	if _, _, err := stub.GetQueryResultWithPagination(`{"selector":{}}`, 2, "bogus"); err == nil {
+		t.Fatal("invalid bookmark accepted")
+	}
+	if _, _, err := stub.GetQueryResultWithPagination(`{"selector":{}}`, 0, ""); err == nil {
+		t.Fatal("page size 0 accepted")
+	}
+}
@@ -0,0 +1,222 @@
+package impl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"hello/pkg/contract"
+)
+
+// MemorySnapshot is a copy of the ledger of a MemoryFactoryChain: world
+// states, key versions and history, private data, collection definitions and
+// events. Deployed chaincodes and subscriptions are not part of it.
+type MemorySnapshot struct {
+	data *snapshotData
+}
+
+// snapshotData is the file format of a snapshot. Values are base64 encoded.
+type snapshotData struct {
+	Height      uint64               `json:"height"`
+	Namespaces  []*namespaceSnapshot `json:"namespaces"`
+	Collections map[string][]string  `json:"collections,omitempty"`
+	Events      []*ChaincodeEvent    `json:"events,omitempty"`
+}
+
+// namespaceSnapshot is the world state of a chaincode on a channel. An empty
+// channel or chaincode is the one of stubs created without options.
+type namespaceSnapshot struct {
+	Channel     string                                 `json:"channel,omitempty"`
+	Chaincode   string                                 `json:"chaincode,omitempty"`
+	States      map[string][]byte                      `json:"states"`
+	Versions    map[string]*memoryVersion              `json:"versions,omitempty"`
+	Validations map[string][]byte                      `json:"validations,omitempty"`
+	History     map[string][]*contract.KeyModification `json:"history,omitempty"`
+	Collections map[string]*collectionSnapshot         `json:"collections,omitempty"`
+}
+
+type collectionSnapshot struct {
+	States      map[string][]byte `json:"states"`
+	Validations map[string][]byte `json:"validations,omitempty"`
+}
+
+// Snapshot copies the committed ledger. Later transactions do not change
+// the snapshot.
+func (m *MemoryFactoryChain) Snapshot() *MemorySnapshot {
+	data := &snapshotData{
+		Height:      m.height,
+		Collections: map[string][]string{},
+	}
+	var keys []string
+	for key := range m.namespaces {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ns := m.namespaces[key]
+		channel, chaincode := splitNamespaceKey(key)
+		s := &namespaceSnapshot{
+			Channel:     channel,
+			Chaincode:   chaincode,
+			States:      copyStates(ns.states),
+			Versions:    map[string]*memoryVersion{},
+			Validations: copyStates(ns.validations),
+			History:     map[string][]*contract.KeyModification{},
+			Collections: map[string]*collectionSnapshot{},
+		}
+		for k, v := range ns.versions {
+			version := *v
+			s.Versions[k] = &version
+		}
+		for k, mods := range ns.history {
+			s.History[k] = copyHistory(mods)
+		}
+		for name, c := range ns.collections {
+			s.Collections[name] = &collectionSnapshot{States: copyStates(c.states), Validations: copyStates(c.validations)}
+		}
+		data.Namespaces = append(data.Namespaces, s)
+	}
+	for name, members := range m.collections {
+		ids := []string{}
+		for id := range members {
+			ids = append(ids, id)
+		}
+		data.Collections[name] = ids
+	}
+	for _, e := range m.events {
+		event := *e
+		event.Payload = copyBytes(e.Payload)
+		data.Events = append(data.Events, &event)
+	}
+	return &MemorySnapshot{data: data}
+}
+
+// Restore replaces the ledger with a copy of the snapshot, so that the
+// snapshot can be restored again, e.g. into a new chain for every test. Null
+// entries of a hand-written snapshot are skipped.
+func (m *MemoryFactoryChain) Restore(snapshot *MemorySnapshot) {
+	data := snapshot.data
+	m.height = data.Height
+
+	m.namespaces = map[string]*memoryNamespace{}
+	for _, s := range data.Namespaces {
+		if s == nil {
+			continue
+		}
+		channel, chaincode := s.Channel, s.Chaincode
+		if channel == "" {
+			channel = defaultChannelID
+		}
+		if chaincode == "" {
+			chaincode = defaultChaincode
+		}
+		ns := m.namespace(namespaceKey(channel, chaincode))
+		for k, v := range s.States {
+			ns.states[k] = copyBytes(v)
+		}
+		for k, v := range s.Validations {
+			ns.validations[k] = copyBytes(v)
+		}
+		for k, v := range s.Versions {
+			if v != nil {
+				version := *v
+				ns.versions[k] = &version
+			}
+		}
+		for k, mods := range s.History {
+			ns.history[k] = copyHistory(mods)
+		}
+		for name, c := range s.Collections {
+			if c == nil {
+				continue
+			}
+			collection := ns.collection(name)
+			for k, v := range c.States {
+				collection.states[k] = copyBytes(v)
+			}
+			for k, v := range c.Validations {
+				collection.validations[k] = copyBytes(v)
+			}
+		}
+	}
+
+	m.collections = map[string]map[string]bool{}
+	for name, ids := range data.Collections {
+		m.DefineCollection(name, ids...)
+	}
+
+	m.events = nil
+	for _, e := range data.Events {
+		if e == nil {
+			continue
+		}
+		event := *e
+		event.Payload = copyBytes(e.Payload)
+		m.events = append(m.events, &event)
+	}
+}
+
+// Save writes the snapshot to a JSON file.
+func (s *MemorySnapshot) Save(path string) error {
+	buf, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+// LoadSnapshot reads a snapshot saved with MemorySnapshot.Save.
+func LoadSnapshot(path string) (*MemorySnapshot, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data := &snapshotData{}
+	if err = json.Unmarshal(buf, data); err != nil {
+		return nil, err
+	}
+	return &MemorySnapshot{data: data}, nil
+}
+
+// Save writes a snapshot of the ledger to a JSON file.
+func (m *MemoryFactoryChain) Save(path string) error {
+	return m.Snapshot().Save(path)
+}
+
+// Load replaces the ledger with a snapshot read from a JSON file.
+func (m *MemoryFactoryChain) Load(path string) error {
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	m.Restore(snapshot)
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+func copyStates(states map[string][]byte) map[string][]byte {
+	c := make(map[string][]byte, len(states))
+	for k, v := range states {
+		c[k] = copyBytes(v)
+	}
+	return c
+}
+
+func copyHistory(mods []*contract.KeyModification) []*contract.KeyModification {
+	c := make([]*contract.KeyModification, 0, len(mods))
+	for _, mod := range mods {
+		if mod == nil {
+			continue
+		}
+		km := *mod
+		km.Value = copyBytes(mod.Value)
+		c = append(c, &km)
+	}
+	return c
+}
@@ -0,0 +1,116 @@
+package impl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemorySnapshotRestore(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation())
+	putStates(t, chain, "a", "b")
+	emitEvents(t, chain, "created")
+	snapshot := chain.Snapshot()
+
+	putStates(t, chain, "c")
+	emitEvents(t, chain, "updated")
+
+	for i := 0; i < 2; i++ {
+		restored := NewMemoryFactoryChain()
+		restored.Restore(snapshot)
+		it, err := restored.NewStub(testAddr).GetStateByRange("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := keysOf(t, it); !reflect.DeepEqual(got, []string{"a", "b"}) {
+			t.Fatalf("keys = %v", got)
+		}
+		if names := eventNames(restored.Events(EventFilter{})); !reflect.DeepEqual(names, []string{"created"}) {
+			t.Fatalf("events = %v", names)
+		}
+		if restored.height != 2 {
+			t.Fatalf("height = %d", restored.height)
+		}
+		putStates(t, restored, "d")
+	}
+}
+
+func TestMemorySnapshotSaveLoad(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation(), WithTxIDs(SequentialTxIDs("tx")))
+	chain.DefineCollection("secrets", "Org1MSP")
+	stub := chain.NewStub(testAddr, WithChannel("other"), WithChaincode("cc"))
+	if err := stub.PutState("k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.PutPrivateData("secrets", "p", []byte("s")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.SetEvent("created", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	if err := chain.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"channel": "other"`, `"chaincode": "cc"`, `"txId": "tx1"`, `"name": "created"`} {
+		if !strings.Contains(string(buf), field) {
+			t.Fatalf("%s not in %s", field, buf)
+		}
+	}
+
+	loaded := NewMemoryFactoryChain()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	reader := loaded.NewStub(testAddr, WithChannel("other"), WithChaincode("cc"), WithMSPID("Org1MSP"))
+	if v, _ := reader.GetState("k"); string(v) != "v" {
+		t.Fatalf("state = %q", v)
+	}
+	if v, err := reader.GetPrivateData("secrets", "p"); err != nil || string(v) != "s" {
+		t.Fatalf("private data = %q, %v", v, err)
+	}
+	if !reflect.DeepEqual(loaded.Events(EventFilter{}), chain.Events(EventFilter{})) {
+		t.Fatalf("events = %v", loaded.Events(EventFilter{}))
+	}
+}
+
+func TestMemorySnapshotFixture(t *testing.T) {
+	fixture := `{
+  "height": 3,
+  "namespaces": [
+    null,
+    {"states": {"a": "MQ=="}, "versions": {"a": null}, "history": {"a": [null]}},
+    {"channel": "other", "chaincode": "cc", "states": {"b": "Mg=="}, "collections": {"secrets": null}}
+  ],
+  "events": [null, {"block": 3, "txId": "tx", "chaincode": "cc", "name": "created"}]
+}`
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := ioutil.WriteFile(path, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	chain := NewMemoryFactoryChain()
+	if err := chain.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := chain.NewStub(testAddr).GetState("a"); string(v) != "1" {
+		t.Fatalf("default namespace a = %q", v)
+	}
+	if v, _ := chain.NewStub(testAddr, WithChannel("other"), WithChaincode("cc")).GetState("b"); string(v) != "2" {
+		t.Fatalf("other/cc b = %q", v)
+	}
+	events := chain.Events(EventFilter{})
+	if len(events) != 1 || events[0].BlockNum != 3 || events[0].ChaincodeID != "cc" || events[0].EventName != "created" {
+		t.Fatalf("events = %v", events)
+	}
+}
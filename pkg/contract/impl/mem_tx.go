@@ -0,0 +1,441 @@
+package impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"hello/pkg/contract"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// transaction is implemented by stubs which buffer their writes until the
+// invocation completes, see FabricChaincode.handler.
+type transaction interface {
+	commit() error
+	rollback()
+}
+
+type memoryWrite struct {
+	value    []byte
+	isDelete bool
+}
+
+func newMemoryWrite(value []byte) *memoryWrite {
+	if value == nil {
+		return &memoryWrite{isDelete: true}
+	}
+	return &memoryWrite{value: value}
+}
+
+// memoryUpdates buffers the writes of a transaction to one namespace.
+type memoryUpdates struct {
+	states      map[string]*memoryWrite
+	validations map[string]*memoryWrite
+	collections map[string]*memoryCollectionUpdates
+}
+
+type memoryCollectionUpdates struct {
+	states      map[string]*memoryWrite
+	validations map[string]*memoryWrite
+}
+
+func (u *memoryUpdates) collection(name string) *memoryCollectionUpdates {
+	c, ok := u.collections[name]
+	if !ok {
+		c = &memoryCollectionUpdates{states: map[string]*memoryWrite{}, validations: map[string]*memoryWrite{}}
+		u.collections[name] = c
+	}
+	return c
+}
+
+type memoryEvent struct {
+	name    string
+	payload []byte
+}
+
+// memoryRangeQuery records a range query of a transaction, which is
+// executed again at validation to detect phantom reads.
+type memoryRangeQuery struct {
+	ns        string
+	filter    func(k string) bool
+	keys      []string
+	versions  []*memoryVersion
+	exhausted bool
+}
+
+// memoryTx is the read/write set of the transaction simulated by a stub.
+// Stubs of called chaincodes share the transaction of the caller.
+type memoryTx struct {
+	reads   map[string]map[string]*memoryVersion // channel/chaincode -> key -> version read
+	ranges  []*memoryRangeQuery
+	updates map[string]*memoryUpdates // channel/chaincode -> writes
+	event   *memoryEvent
+	events  []*contract.Event // added with AddEvent, see flushEvents
+}
+
+func newMemoryTx() *memoryTx {
+	return &memoryTx{
+		reads:   map[string]map[string]*memoryVersion{},
+		updates: map[string]*memoryUpdates{},
+	}
+}
+
+// clone copies the read/write set, e.g. for a transaction waiting in a
+// block.
+func (tx *memoryTx) clone() *memoryTx {
+	c := newMemoryTx()
+	for ns, reads := range tx.reads {
+		c.reads[ns] = map[string]*memoryVersion{}
+		for key, v := range reads {
+			c.reads[ns][key] = v
+		}
+	}
+	for _, rq := range tx.ranges {
+		r := *rq
+		r.keys = append([]string(nil), rq.keys...)
+		r.versions = append([]*memoryVersion(nil), rq.versions...)
+		c.ranges = append(c.ranges, &r)
+	}
+	c.addWrites(tx)
+	c.event = tx.event
+	c.events = append([]*contract.Event(nil), tx.events...)
+	return c
+}
+
+// read records the version of the committed key read by the stub.
+func (m *memoryStub) read(key string) {
+	ns := namespaceKey(m.channel, m.chaincode)
+	reads, ok := m.tx.reads[ns]
+	if !ok {
+		reads = map[string]*memoryVersion{}
+		m.tx.reads[ns] = reads
+	}
+	if _, ok := reads[key]; !ok {
+		reads[key] = m.ns().versions[key]
+	}
+}
+
+// rangeQuery returns an iterator over kvs which records the keys it yields
+// for the phantom read check.
+func (m *memoryStub) rangeQuery(filter func(k string) bool, kvs []*contract.StateKV) *memoryStateIterator {
+	rq := &memoryRangeQuery{ns: namespaceKey(m.channel, m.chaincode), filter: filter}
+	m.tx.ranges = append(m.tx.ranges, rq)
+	versions := m.ns().versions
+	return &memoryStateIterator{kvs: kvs, onNext: func(kv *contract.StateKV) {
+		rq.keys = append(rq.keys, kv.Key)
+		rq.versions = append(rq.versions, versions[kv.Key])
+	}, onExhausted: func() {
+		rq.exhausted = true
+	}}
+}
+
+// validate checks the read set of the transaction against the world state
+// like the validator of a peer.
+func (m *memoryStub) validate() pb.TxValidationCode {
+	for ns, reads := range m.tx.reads {
+		versions := m.factory.namespace(ns).versions
+		for key, v := range reads {
+			if !sameVersion(v, versions[key]) {
+				return pb.TxValidationCode_MVCC_READ_CONFLICT
+			}
+		}
+	}
+
+	for _, rq := range m.tx.ranges {
+		ns := m.factory.namespace(rq.ns)
+		current := scanStates(ns.states, rq.filter)
+		if !rq.exhausted {
+			var last string
+			if len(rq.keys) > 0 {
+				last = rq.keys[len(rq.keys)-1]
+			}
+			n := 0
+			for n < len(current) && len(rq.keys) > 0 && current[n].Key <= last {
+				n++
+			}
+			current = current[:n]
+		}
+		if len(current) != len(rq.keys) {
+			return pb.TxValidationCode_PHANTOM_READ_CONFLICT
+		}
+		for i, kv := range current {
+			if kv.Key != rq.keys[i] || !sameVersion(ns.versions[kv.Key], rq.versions[i]) {
+				return pb.TxValidationCode_PHANTOM_READ_CONFLICT
+			}
+		}
+	}
+	return pb.TxValidationCode_VALID
+}
+
+// updates returns the write set of the namespace of the stub.
+func (m *memoryStub) updates() *memoryUpdates {
+	return m.tx.namespaceUpdates(namespaceKey(m.channel, m.chaincode))
+}
+
+func (tx *memoryTx) namespaceUpdates(ns string) *memoryUpdates {
+	u, ok := tx.updates[ns]
+	if !ok {
+		u = &memoryUpdates{
+			states:      map[string]*memoryWrite{},
+			validations: map[string]*memoryWrite{},
+			collections: map[string]*memoryCollectionUpdates{},
+		}
+		tx.updates[ns] = u
+	}
+	return u
+}
+
+// merge adds the read set of the transaction of a called chaincode, and its
+// write set if the call succeeded.
+func (tx *memoryTx) merge(child *memoryTx, writes bool) {
+	for ns, reads := range child.reads {
+		parent, ok := tx.reads[ns]
+		if !ok {
+			tx.reads[ns] = reads
+			continue
+		}
+		for key, v := range reads {
+			if _, ok := parent[key]; !ok {
+				parent[key] = v
+			}
+		}
+	}
+	tx.ranges = append(tx.ranges, child.ranges...)
+	if writes {
+		tx.addWrites(child)
+	}
+}
+
+// addWrites adds the write set of other, overwriting the writes to the same
+// keys.
+func (tx *memoryTx) addWrites(other *memoryTx) {
+	for ns, cu := range other.updates {
+		u := tx.namespaceUpdates(ns)
+		copyWrites(u.states, cu.states)
+		copyWrites(u.validations, cu.validations)
+		for name, c := range cu.collections {
+			copyWrites(u.collection(name).states, c.states)
+			copyWrites(u.collection(name).validations, c.validations)
+		}
+	}
+}
+
+func copyWrites(dst, src map[string]*memoryWrite) {
+	for key, w := range src {
+		dst[key] = w
+	}
+}
+
+// buffered reports whether the writes wait for the transaction to commit.
+// They always do while the RPC handler runs the stub and in a called
+// chaincode, so that a failed invocation leaves no writes behind. A stub used
+// directly buffers them with WithTxSimulation or in block mode only.
+func (m *memoryStub) buffered() bool {
+	return m.simulate || m.parent != nil || m.factory.simulateTx || m.block != nil
+}
+
+// flush applies the writes made so far unless they are buffered. The event
+// waits for the transaction to commit either way.
+func (m *memoryStub) flush() {
+	if m.buffered() {
+		return
+	}
+	m.factory.height++
+	m.write(&memoryVersion{BlockNum: m.factory.height})
+	m.tx.updates = map[string]*memoryUpdates{}
+}
+
+// commit applies the write set to the world state in a block of its own.
+// In block mode the transaction is queued for MemoryBlock.Commit instead.
+// The stub of a called chaincode hands its transaction to the caller.
+func (m *memoryStub) commit() error {
+	if m.parent != nil {
+		m.parent.tx.merge(m.tx, true)
+		m.parent.flush()
+		m.tx = newMemoryTx()
+		return nil
+	}
+	if m.block != nil {
+		return m.block.submit(m)
+	}
+
+	if m.buffered() || m.tx.event != nil {
+		m.factory.height++
+		m.apply(&memoryVersion{BlockNum: m.factory.height})
+	}
+	m.reset()
+	return nil
+}
+
+// reset starts the next transaction of the stub.
+func (m *memoryStub) reset() {
+	m.tx = newMemoryTx()
+	m.txID = m.factory.txIDs()
+	m.t = m.factory.clock()
+}
+
+// apply writes the write set of the transaction with the version and emits
+// its event.
+func (m *memoryStub) apply(version *memoryVersion) {
+	m.write(version)
+	if m.tx.event != nil {
+		m.factory.emit(&ChaincodeEvent{
+			BlockNum:    version.BlockNum,
+			TxID:        m.GetTxID(),
+			ChaincodeID: m.chaincode,
+			EventName:   m.tx.event.name,
+			Payload:     m.tx.event.payload,
+		})
+	}
+}
+
+// write applies the write set of the transaction with the version.
+func (m *memoryStub) write(version *memoryVersion) {
+	var keys []string
+	for key := range m.tx.updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m.applyUpdates(m.factory.namespace(key), m.tx.updates[key], version)
+	}
+}
+
+func (m *memoryStub) applyUpdates(ns *memoryNamespace, u *memoryUpdates, version *memoryVersion) {
+	for key, w := range u.states {
+		if w.isDelete {
+			delete(ns.states, key)
+			delete(ns.versions, key)
+		} else {
+			ns.states[key] = w.value
+			ns.versions[key] = version
+		}
+		ns.history[key] = append(ns.history[key], &contract.KeyModification{
+			TxID:      m.GetTxID(),
+			Timestamp: m.t,
+			Value:     w.value,
+			IsDelete:  w.isDelete,
+		})
+	}
+	applyWrites(ns.validations, u.validations)
+
+	for name, cu := range u.collections {
+		c := ns.collection(name)
+		applyWrites(c.states, cu.states)
+		applyWrites(c.validations, cu.validations)
+	}
+}
+
+func applyWrites(states map[string][]byte, writes map[string]*memoryWrite) {
+	for key, w := range writes {
+		if w.isDelete {
+			delete(states, key)
+		} else {
+			states[key] = w.value
+		}
+	}
+}
+
+// rollback discards the write set. The stub of a called chaincode hands
+// only its reads to the caller, whose transaction goes on.
+func (m *memoryStub) rollback() {
+	if m.parent != nil {
+		m.parent.tx.merge(m.tx, false)
+		m.tx = newMemoryTx()
+		return
+	}
+	m.reset()
+}
+
+// Commit ends the transaction of a stub used without the RPC handler, e.g.
+// when a test calls the service methods directly. It applies the buffered
+// writes and emits the event of the transaction.
+func (m *MemoryFactoryChain) Commit(stub contract.IContractStub) error {
+	s, ok := stub.(*memoryStub)
+	if !ok {
+		return fmt.Errorf("not a memory stub: %T", stub)
+	}
+	if err := flushEvents(s); err != nil {
+		return err
+	}
+	return s.commit()
+}
+
+// Rollback discards the writes of a stub used without the RPC handler.
+func (m *MemoryFactoryChain) Rollback(stub contract.IContractStub) {
+	if s, ok := stub.(*memoryStub); ok {
+		s.rollback()
+	}
+}
+
+// Invoke calls method of the chaincode deployed for the stub through the
+// RPC handler with params marshalled to JSON. The transaction is simulated
+// like with WithTxSimulation: it is committed when the handler succeeds and
+// discarded otherwise.
+func (m *MemoryFactoryChain) Invoke(stub contract.IContractStub, method string, params ...interface{}) pb.Response {
+	args, err := makeArgs(method, params...)
+	if err != nil {
+		return pb.Response{Status: 500, Message: err.Error()}
+	}
+	return m.InvokeArgs(stub, args)
+}
+
+// InvokeArgs is like Invoke with the raw arguments of the proposal.
+func (m *MemoryFactoryChain) InvokeArgs(stub contract.IContractStub, args [][]byte) pb.Response {
+	s, cc, err := m.deployed(stub)
+	if err != nil {
+		return pb.Response{Status: 500, Message: err.Error()}
+	}
+	s.args = args
+	s.simulate = true
+	defer func() { s.simulate = false }()
+	return cc.invoke(s)
+}
+
+// Init calls the init function of the chaincode deployed for the stub like
+// Invoke.
+func (m *MemoryFactoryChain) Init(stub contract.IContractStub, params ...interface{}) pb.Response {
+	args, err := makeArgs("Init", params...)
+	if err != nil {
+		return pb.Response{Status: 500, Message: err.Error()}
+	}
+	return m.InitArgs(stub, args)
+}
+
+// InitArgs is like Init with the raw arguments of the proposal.
+func (m *MemoryFactoryChain) InitArgs(stub contract.IContractStub, args [][]byte) pb.Response {
+	s, cc, err := m.deployed(stub)
+	if err != nil {
+		return pb.Response{Status: 500, Message: err.Error()}
+	}
+	s.args = args
+	s.simulate = true
+	defer func() { s.simulate = false }()
+	return cc.init(s)
+}
+
+// deployed returns the memory stub and the chaincode deployed for it.
+func (m *MemoryFactoryChain) deployed(stub contract.IContractStub) (*memoryStub, *FabricChaincode, error) {
+	s, ok := stub.(*memoryStub)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a memory stub: %T", stub)
+	}
+	cc, ok := m.contracts[namespaceKey(s.channel, s.chaincode)]
+	if !ok {
+		return nil, nil, fmt.Errorf("chaincode %s not found on channel %s", s.chaincode, s.channel)
+	}
+	return s, cc, nil
+}
+
+func makeArgs(method string, params ...interface{}) ([][]byte, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	buf, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{[]byte(method), buf}, nil
+}
@@ -0,0 +1,161 @@
+package impl
+
+import (
+	"errors"
+	"testing"
+
+	"hello/pkg/contract"
+)
+
+type Writer struct{}
+
+func (w *Writer) Put(stub contract.IContractStub, key, value string) (bool, error) {
+	return true, stub.PutState(key, []byte(value))
+}
+
+func (w *Writer) PutAndFail(stub contract.IContractStub, key, value string) (bool, error) {
+	if err := stub.PutState(key, []byte(value)); err != nil {
+		return false, err
+	}
+	return false, errors.New("failed")
+}
+
+func (w *Writer) PutAndPanic(stub contract.IContractStub, key, value string) (bool, error) {
+	if err := stub.PutState(key, []byte(value)); err != nil {
+		return false, err
+	}
+	panic("boom")
+}
+
+func (w *Writer) ReadOwnWrite(stub contract.IContractStub, key, value string) (string, error) {
+	if err := stub.PutState(key, []byte(value)); err != nil {
+		return "", err
+	}
+	v, err := stub.GetState(key)
+	return string(v), err
+}
+
+func TestMemoryStubWritesAppliedImmediately(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	stub := chain.NewStub(testAddr)
+	if err := stub.PutState("k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := stub.GetState("k"); string(v) != "v" {
+		t.Fatalf("own write not visible: %q", v)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "v" {
+		t.Fatalf("write not visible to other stubs: %q", got)
+	}
+	chain.Rollback(stub)
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "v" {
+		t.Fatalf("applied write rolled back: %q", got)
+	}
+
+	cc := NewFabricChaincode()
+	cc.Register(&Writer{})
+	chain.Deploy(defaultChaincode, cc)
+	if resp := chain.Invoke(stub, "Writer.PutAndFail", "k", "x"); resp.Status == 200 {
+		t.Fatal("failing method succeeded")
+	}
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "v" {
+		t.Fatalf("write of a failed invocation applied: %q", got)
+	}
+	if err := stub.PutState("k", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "v2" {
+		t.Fatalf("write after an invocation not applied: %q", got)
+	}
+}
+
+func TestMemoryStubTxSimulation(t *testing.T) {
+	chain := NewMemoryFactoryChain(WithTxSimulation())
+	stub := chain.NewStub(testAddr)
+	if err := stub.PutState("k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := stub.GetState("k"); v != nil {
+		t.Fatalf("uncommitted write visible: %q", v)
+	}
+	chain.Rollback(stub)
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "" {
+		t.Fatalf("rolled back write applied: %q", got)
+	}
+
+	if err := stub.PutState("k", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "k"); got != "v2" {
+		t.Fatalf("committed write not applied: %q", got)
+	}
+}
+
+func TestMemoryStubTxSimulationThroughHandler(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := NewFabricChaincode()
+	cc.Register(&Writer{})
+	chain.Deploy(defaultChaincode, cc)
+	stub := chain.NewStub(testAddr)
+
+	if resp := chain.Invoke(stub, "Writer.PutAndFail", "a", "x"); resp.Status == 200 {
+		t.Fatal("failing method succeeded")
+	}
+	if resp := chain.Invoke(stub, "Writer.PutAndPanic", "b", "x"); resp.Status == 200 {
+		t.Fatal("panicking method succeeded")
+	}
+	resp := chain.Invoke(stub, "Writer.ReadOwnWrite", "c", "x")
+	if resp.Status != 200 || string(resp.Payload) != `""` {
+		t.Fatalf("got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+	for key, want := range map[string]string{"a": "", "b": "", "c": "x"} {
+		if got := stateOf(t, chain, defaultChaincode, key); got != want {
+			t.Errorf("state %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+type TolerantCaller struct{}
+
+// Call ignores the error of the callee.
+func (c *TolerantCaller) Call(stub contract.IContractStub, method string) (bool, error) {
+	args, err := makeArgs(method, "n", "x")
+	if err != nil {
+		return false, err
+	}
+	_, err = stub.InvokeContract("writer", args, "")
+	return err == nil, stub.PutState("called", []byte("yes"))
+}
+
+func TestMemoryStubInvokeContractFailureDiscardsWrites(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	writer := NewFabricChaincode()
+	writer.Register(&Writer{})
+	chain.Deploy("writer", writer)
+	caller := NewFabricChaincode()
+	caller.Register(&TolerantCaller{})
+	chain.Deploy(defaultChaincode, caller)
+
+	if resp := chain.Invoke(chain.NewStub(testAddr), "TolerantCaller.Call", "Writer.PutAndFail"); resp.Status != 200 {
+		t.Fatal(resp.Message)
+	}
+	if got := stateOf(t, chain, "writer", "n"); got != "" {
+		t.Fatalf("write of the failed callee applied: %q", got)
+	}
+	if got := stateOf(t, chain, defaultChaincode, "called"); got != "yes" {
+		t.Fatalf("write of the caller not applied: %q", got)
+	}
+
+	if resp := chain.Invoke(chain.NewStub(testAddr), "TolerantCaller.Call", "Writer.Put"); resp.Status != 200 {
+		t.Fatal(resp.Message)
+	}
+	if got := stateOf(t, chain, "writer", "n"); got != "x" {
+		t.Fatalf("write of the callee not applied: %q", got)
+	}
+}
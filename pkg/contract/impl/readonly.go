@@ -0,0 +1,75 @@
+package impl
+
+import (
+	"strings"
+
+	"hello/pkg/contract"
+)
+
+// ReadOnly marks registered services, e.g. "MyService", or methods, e.g.
+// "MyService.GetPrice", as queries. Their stub returns contract.ErrReadOnly
+// on writes, events and calls of other chaincodes, whose writes it could not
+// restrict.
+func (cc *FabricChaincode) ReadOnly(serviceOrMethods ...string) {
+	if cc.readOnly == nil {
+		cc.readOnly = map[string]bool{}
+	}
+	for _, name := range serviceOrMethods {
+		cc.mustBeRegistered("ReadOnly", name)
+		cc.readOnly[name] = true
+	}
+}
+
+func (cc *FabricChaincode) isReadOnly(method string) bool {
+	if cc.readOnly[method] {
+		return true
+	}
+	dot := strings.LastIndex(method, ".")
+	return dot >= 0 && cc.readOnly[method[:dot]]
+}
+
+// readOnlyStub rejects the writes of a read-only method.
+type readOnlyStub struct {
+	contract.IContractStub
+}
+
+func (s *readOnlyStub) PutState(key string, value []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) DelState(key string) ([]byte, error) {
+	return nil, contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) SetStateValidationParameter(key string, ep []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) PutPrivateData(collection, key string, value []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) DelPrivateData(collection, key string) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) SetEvent(name string, payload []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) AddEvent(name string, payload []byte) error {
+	return contract.ErrReadOnly
+}
+
+func (s *readOnlyStub) InvokeContract(contractName string, args [][]byte, channel string) ([]byte, error) {
+	return nil, contract.ErrReadOnly
+}
+
+// GetOriginStub returns nil, the origin stub would allow writes.
+func (s *readOnlyStub) GetOriginStub() interface{} {
+	return nil
+}
@@ -0,0 +1,128 @@
+package impl
+
+import (
+	"strings"
+	"testing"
+
+	"hello/pkg/contract"
+	"hello/pkg/rpc"
+)
+
+type Shop struct{}
+
+func (s *Shop) Price(stub contract.IContractStub) (string, error) {
+	v, err := stub.GetState("price")
+	return string(v), err
+}
+
+func (s *Shop) SetPrice(stub contract.IContractStub, price string) (bool, error) {
+	return true, stub.PutState("price", []byte(price))
+}
+
+func (s *Shop) Announce(ctx contract.Context) (bool, error) {
+	return true, ctx.AddEvent("shop", "sale", "now")
+}
+
+func (s *Shop) Count(stub contract.IContractStub) (string, error) {
+	args, err := makeArgs("Counter.Inc", "n")
+	if err != nil {
+		return "", err
+	}
+	payload, err := stub.InvokeContract("counter", args, "")
+	return string(payload), err
+}
+
+func (s *Shop) Origin(stub contract.IContractStub) (bool, error) {
+	return stub.GetOriginStub() == nil, nil
+}
+
+func deployShop(chain *MemoryFactoryChain) *FabricChaincode {
+	cc := NewFabricChaincode()
+	cc.Register(&Shop{})
+	chain.Deploy(defaultChaincode, cc)
+
+	counter := NewFabricChaincode()
+	counter.Register(&Counter{})
+	chain.Deploy("counter", counter)
+	return cc
+}
+
+func TestReadOnly(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployShop(chain)
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Shop.SetPrice", "10"); resp.Status != 200 {
+		t.Fatalf("got %d %s", resp.Status, resp.Message)
+	}
+	cc.ReadOnly("Shop")
+
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Shop.Price"); resp.Status != 200 || string(resp.Payload) != `"10"` {
+		t.Fatalf("read: got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+	for method, params := range map[string][]interface{}{"Shop.SetPrice": {"20"}, "Shop.Announce": nil, "Shop.Count": nil} {
+		resp := chain.Invoke(chain.NewStub(testAddr), method, params...)
+		if resp.Status == 200 || resp.Message != contract.ErrReadOnly.Error() {
+			t.Errorf("%s: got %d %s", method, resp.Status, resp.Message)
+		}
+	}
+	if got := stateOf(t, chain, defaultChaincode, "price"); got != "10" {
+		t.Fatalf("price = %q", got)
+	}
+	if got := stateOf(t, chain, "counter", "n"); got != "" {
+		t.Fatalf("called chaincode wrote n = %q", got)
+	}
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Shop.Origin"); string(resp.Payload) != "true" {
+		t.Fatalf("origin stub: got %d %s %s", resp.Status, resp.Payload, resp.Message)
+	}
+}
+
+func TestReadOnlyMethod(t *testing.T) {
+	chain := NewMemoryFactoryChain()
+	cc := deployShop(chain)
+	cc.ReadOnly("Shop.Price", "Shop.Count")
+
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Shop.SetPrice", "10"); resp.Status != 200 {
+		t.Fatalf("writable method: got %d %s", resp.Status, resp.Message)
+	}
+	if resp := chain.Invoke(chain.NewStub(testAddr), "Shop.Count"); resp.Message != contract.ErrReadOnly.Error() {
+		t.Fatalf("read-only method: got %d %s", resp.Status, resp.Message)
+	}
+}
+
+// mustPanic fails unless f panics with a message containing want.
+func mustPanic(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		re := recover()
+		if re == nil {
+			t.Fatalf("no panic, want %q", want)
+		}
+		if msg, _ := re.(string); !strings.Contains(msg, want) {
+			t.Fatalf("panic %v, want %q", re, want)
+		}
+	}()
+	f()
+}
+
+func TestUnknownNamesPanic(t *testing.T) {
+	cc := NewFabricChaincode()
+	cc.Register(&Shop{})
+	cc.RegisterInit(func(stub contract.IContractStub) (bool, error) { return true, nil })
+	noop := func(ctx contract.Context, req *rpc.Request, next Handler) (interface{}, error) {
+		return next(ctx, req)
+	}
+
+	for _, name := range []string{"Shop", "Shop.Price", initServiceMethod, "Chaincode"} {
+		cc.ReadOnly(name)
+		cc.Allow(name, &ACL{})
+		cc.UseFor(name, noop)
+	}
+
+	for _, name := range []string{"Shops", "Shop.price", "Shop.Price.X", "Price"} {
+		mustPanic(t, "ReadOnly: "+name+" is not a registered", func() { cc.ReadOnly(name) })
+		mustPanic(t, "Allow: "+name+" is not a registered", func() { cc.Allow(name, &ACL{}) })
+		mustPanic(t, "UseFor: "+name+" is not a registered", func() { cc.UseFor(name, noop) })
+	}
+	mustPanic(t, "needs a service or method", func() { cc.ReadOnly("") })
+	mustPanic(t, "needs an ACL", func() { cc.Allow("Shop", nil) })
+}
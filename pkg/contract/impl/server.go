@@ -0,0 +1,187 @@
+package impl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Environment of a chaincode running as an external service. The TLS
+// variables hold file paths.
+const (
+	EnvChaincodeID   = "CHAINCODE_ID"
+	EnvServerAddress = "CHAINCODE_SERVER_ADDRESS"
+	EnvTLSDisabled   = "CHAINCODE_TLS_DISABLED"
+	EnvTLSKey        = "CHAINCODE_TLS_KEY"
+	EnvTLSCert       = "CHAINCODE_TLS_CERT"
+	EnvClientCACert  = "CHAINCODE_CLIENT_CA_CERT"
+	EnvHealthAddress = "CHAINCODE_HEALTH_ADDRESS"
+)
+
+const maxGrpcMessageSize = 100 * 1024 * 1024 // like the peer
+
+// StartServer runs the chaincode as an external service when
+// CHAINCODE_SERVER_ADDRESS is set and like Start otherwise. TLS is enabled
+// when CHAINCODE_TLS_KEY and CHAINCODE_TLS_CERT are set, client certificates
+// are verified when CHAINCODE_CLIENT_CA_CERT is set too. With
+// CHAINCODE_HEALTH_ADDRESS an HTTP endpoint answers GET /healthz.
+//
+// On SIGTERM or interrupt the server finishes the running transactions and
+// StartServer returns.
+func (cc *FabricChaincode) StartServer() {
+	address := os.Getenv(EnvServerAddress)
+	if address == "" {
+		cc.Start()
+		return
+	}
+
+	err := cc.serve(os.Getenv(EnvChaincodeID), address)
+	if err != nil {
+		panic("Error starting chaincode server - " + err.Error())
+	}
+}
+
+func (cc *FabricChaincode) serve(ccid, address string) error {
+	if ccid == "" {
+		return fmt.Errorf("%s must be specified", EnvChaincodeID)
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: time.Minute, Timeout: 20 * time.Second}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: time.Minute, PermitWithoutStream: true}),
+		grpc.MaxSendMsgSize(maxGrpcMessageSize),
+		grpc.MaxRecvMsgSize(maxGrpcMessageSize),
+		grpc.ConnectionTimeout(5 * time.Second),
+	}
+	tlsCfg, err := loadServerTLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterChaincodeServer(server, &shim.ChaincodeServer{CCID: ccid, Address: address, CC: cc})
+
+	var healthy int32 = 1
+	var health *http.Server
+	if healthAddress := os.Getenv(EnvHealthAddress); healthAddress != "" {
+		health = newHealthServer(healthAddress, &healthy)
+		go func() {
+			if err := health.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("ERR: health endpoint error:%s\n", err.Error())
+			}
+		}()
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(stop)
+	done := make(chan struct{})
+	go shutdownOnSignal(stop, done, func(sig os.Signal) {
+		log.Printf("INFO: received %s, shutting down\n", sig)
+		atomic.StoreInt32(&healthy, 0)
+		server.GracefulStop()
+	})
+
+	log.Printf("INFO: chaincode %s listening on %s, tls:%v\n", ccid, address, tlsCfg != nil)
+	err = server.Serve(lis)
+	close(done)
+	if health != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = health.Shutdown(ctx)
+	}
+	return err
+}
+
+// shutdownOnSignal calls shutdown on the first signal. It returns without
+// calling it once done is closed, i.e. when the server stopped on its own.
+func shutdownOnSignal(stop <-chan os.Signal, done <-chan struct{}, shutdown func(sig os.Signal)) {
+	select {
+	case sig := <-stop:
+		shutdown(sig)
+	case <-done:
+	}
+}
+
+// newHealthServer answers GET /healthz with 200 while the chaincode server
+// is running and with 503 once it is shutting down.
+func newHealthServer(address string, healthy *int32) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(healthy) == 0 {
+			http.Error(w, "SHUTTING_DOWN", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	})
+	return &http.Server{Addr: address, Handler: mux}
+}
+
+// loadServerTLSConfig returns the TLS config of the chaincode server like
+// the one of shim.ChaincodeServer, or nil if TLS is not configured.
+func loadServerTLSConfig() (*tls.Config, error) {
+	keyFile, certFile := os.Getenv(EnvTLSKey), os.Getenv(EnvTLSCert)
+	if strings.EqualFold(os.Getenv(EnvTLSDisabled), "true") || (keyFile == "" && certFile == "") {
+		return nil, nil
+	}
+	if keyFile == "" || certFile == "" {
+		return nil, fmt.Errorf("both %s and %s must be specified", EnvTLSKey, EnvTLSCert)
+	}
+
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key pair: %s", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:             tls.VersionTLS12,
+		Certificates:           []tls.Certificate{pair},
+		SessionTicketsDisabled: true,
+	}
+	if caFile := os.Getenv(EnvClientCACert); caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("failed to load client CA cert")
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
@@ -0,0 +1,74 @@
+package impl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownOnSignal(t *testing.T) {
+	stop := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	got := make(chan os.Signal, 1)
+	returned := make(chan struct{})
+	go func() {
+		shutdownOnSignal(stop, done, func(sig os.Signal) { got <- sig })
+		close(returned)
+	}()
+
+	stop <- syscall.SIGTERM
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("shutdownOnSignal did not return on a signal")
+	}
+	if sig := <-got; sig != syscall.SIGTERM {
+		t.Fatalf("shutdown with %v", sig)
+	}
+}
+
+func TestShutdownOnSignalServerStopped(t *testing.T) {
+	stop := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	returned := make(chan struct{})
+	go func() {
+		shutdownOnSignal(stop, done, func(sig os.Signal) { t.Errorf("shutdown with %v", sig) })
+		close(returned)
+	}()
+
+	close(done)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("shutdownOnSignal did not return after the server stopped")
+	}
+}
+
+func TestServeWithoutChaincodeID(t *testing.T) {
+	err := NewFabricChaincode().serve("", "127.0.0.1:0")
+	if err == nil || !strings.Contains(err.Error(), EnvChaincodeID) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestHealthServer(t *testing.T) {
+	var healthy int32 = 1
+	handler := newHealthServer("", &healthy).Handler
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthy: got %d", rec.Code)
+	}
+
+	healthy = 0
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("shutting down: got %d", rec.Code)
+	}
+}
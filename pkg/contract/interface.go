@@ -0,0 +1,43 @@
+package contract
+
+import (
+	"time"
+
+	"hello/pkg/contract/identity"
+)
+
+type IContractStub interface {
+	GetArgs() [][]byte
+	GetTxID() string
+	GetChannelID() string
+	GetAddress() (string, error)
+	GetClientIdentity() (*identity.ClientIdentity, error)
+	GetTransient() (map[string][]byte, error)
+	GetState(key string) ([]byte, error)
+	PutState(key string, value []byte) error
+	DelState(key string) ([]byte, error)
+	GetStateValidationParameter(key string) ([]byte, error)
+	SetStateValidationParameter(key string, ep []byte) error
+	GetStateByRange(startKey, endKey string) (IStateIterator, error)
+	GetStateByPartialCompositeKey(objectType string, keys []string) (IStateIterator, error)
+	GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (IStateIterator, *QueryMetadata, error)
+	GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (IStateIterator, *QueryMetadata, error)
+	GetQueryResult(query string) (IStateIterator, error)
+	GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (IStateIterator, *QueryMetadata, error)
+	GetHistoryForKey(key string) (IHistoryIterator, error)
+	GetPrivateData(collection, key string) ([]byte, error)
+	GetPrivateDataHash(collection, key string) ([]byte, error)
+	PutPrivateData(collection, key string, value []byte) error
+	DelPrivateData(collection, key string) error
+	GetPrivateDataValidationParameter(collection, key string) ([]byte, error)
+	SetPrivateDataValidationParameter(collection, key string, ep []byte) error
+	GetPrivateDataByRange(collection, startKey, endKey string) (IStateIterator, error)
+	GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (IStateIterator, error)
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
+	SplitCompositeKey(compositeKey string) (string, []string, error)
+	GetTxTimestamp() (time.Time, error)
+	SetEvent(name string, payload []byte) error
+	AddEvent(name string, payload []byte) error
+	InvokeContract(contractName string, args [][]byte, channel string) ([]byte, error)
+	GetOriginStub() interface{}
+}
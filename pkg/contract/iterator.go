@@ -0,0 +1,39 @@
+package contract
+
+import "time"
+
+type StateKV struct {
+	Key   string
+	Value []byte
+}
+
+// QueryMetadata describes one page of a paginated query. Bookmark is passed
+// back to fetch the next page and is empty once the last page was read.
+type QueryMetadata struct {
+	FetchedRecordsCount int32
+	Bookmark            string
+}
+
+// IStateIterator iterates over the key/value pairs returned by a state query.
+// Call Close when done.
+type IStateIterator interface {
+	HasNext() bool
+	Next() (*StateKV, error)
+	Close() error
+}
+
+// KeyModification is one committed write or delete of a key.
+type KeyModification struct {
+	TxID      string
+	Timestamp time.Time
+	Value     []byte
+	IsDelete  bool
+}
+
+// IHistoryIterator iterates over the modifications of a key, newest first.
+// Call Close when done.
+type IHistoryIterator interface {
+	HasNext() bool
+	Next() (*KeyModification, error)
+	Close() error
+}
@@ -0,0 +1,42 @@
+package contract
+
+import "strings"
+
+// roleObjectType is the composite key type of the roles granted on the ledger.
+const roleObjectType = "coral.role"
+
+func makeRoleKey(stub IContractStub, role, address string) (string, error) {
+	return stub.CreateCompositeKey(roleObjectType, []string{role, strings.ToUpper(address)})
+}
+
+// GrantRole grants role to the address on the ledger.
+func GrantRole(stub IContractStub, role, address string) error {
+	key, err := makeRoleKey(stub, role, address)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, []byte{1})
+}
+
+// RevokeRole revokes role from the address.
+func RevokeRole(stub IContractStub, role, address string) error {
+	key, err := makeRoleKey(stub, role, address)
+	if err != nil {
+		return err
+	}
+	_, err = stub.DelState(key)
+	return err
+}
+
+// HasRole returns whether role is granted to the address.
+func HasRole(stub IContractStub, role, address string) (bool, error) {
+	key, err := makeRoleKey(stub, role, address)
+	if err != nil {
+		return false, err
+	}
+	value, err := stub.GetState(key)
+	if err != nil {
+		return false, err
+	}
+	return value != nil, nil
+}
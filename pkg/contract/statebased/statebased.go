@@ -0,0 +1,149 @@
+package statebased
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// RoleType is the role of the principals of an organization.
+type RoleType string
+
+const (
+	RoleTypeMember = RoleType("MEMBER")
+	RoleTypePeer   = RoleType("PEER")
+	RoleTypeAdmin  = RoleType("ADMIN")
+)
+
+var roles = map[RoleType]msp.MSPRole_MSPRoleType{
+	RoleTypeMember: msp.MSPRole_MEMBER,
+	RoleTypePeer:   msp.MSPRole_PEER,
+	RoleTypeAdmin:  msp.MSPRole_ADMIN,
+}
+
+// StateEP builds a key level endorsement policy requiring the signatures of
+// N out of the M listed organizations. By default all of them must endorse.
+type StateEP struct {
+	orgs     map[string]msp.MSPRole_MSPRoleType
+	required int
+}
+
+// NewStateEP creates a builder initialized with policy, usually the result
+// of GetStateValidationParameter. An empty policy starts a new one.
+func NewStateEP(policy []byte) (*StateEP, error) {
+	s := &StateEP{orgs: map[string]msp.MSPRole_MSPRoleType{}}
+	if len(policy) == 0 {
+		return s, nil
+	}
+
+	spe := &common.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(policy, spe); err != nil {
+		return nil, fmt.Errorf("error unmarshalling policy: %s", err)
+	}
+	nOutOf := spe.GetRule().GetNOutOf()
+	if nOutOf == nil {
+		return nil, fmt.Errorf("unsupported policy rule: %v", spe.GetRule())
+	}
+	for _, rule := range nOutOf.Rules {
+		if _, ok := rule.Type.(*common.SignaturePolicy_SignedBy); !ok {
+			return nil, fmt.Errorf("unsupported nested policy rule: %v", rule)
+		}
+	}
+	for _, identity := range spe.Identities {
+		if identity.PrincipalClassification != msp.MSPPrincipal_ROLE {
+			return nil, fmt.Errorf("unsupported principal classification: %v", identity.PrincipalClassification)
+		}
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(identity.Principal, role); err != nil {
+			return nil, fmt.Errorf("error unmarshalling msp role: %s", err)
+		}
+		s.orgs[role.MspIdentifier] = role.Role
+	}
+	if int(nOutOf.N) != len(s.orgs) {
+		s.required = int(nOutOf.N)
+	}
+	return s, nil
+}
+
+// AddOrgs adds the organizations with the given role to the policy,
+// replacing the role of organizations already listed.
+func (s *StateEP) AddOrgs(roleType RoleType, organizations ...string) error {
+	role, ok := roles[roleType]
+	if !ok {
+		return fmt.Errorf("unknown role type %s", roleType)
+	}
+	for _, org := range organizations {
+		s.orgs[org] = role
+	}
+	return nil
+}
+
+// DelOrgs removes the organizations from the policy.
+func (s *StateEP) DelOrgs(organizations ...string) {
+	for _, org := range organizations {
+		delete(s.orgs, org)
+	}
+}
+
+// ListOrgs returns the MSP IDs of the listed organizations in order.
+func (s *StateEP) ListOrgs() []string {
+	orgs := make([]string, 0, len(s.orgs))
+	for org := range s.orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+	return orgs
+}
+
+// SetRequired sets how many of the listed organizations must endorse. Zero
+// requires all of them.
+func (s *StateEP) SetRequired(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid required count %d", n)
+	}
+	s.required = n
+	return nil
+}
+
+// Policy serializes the policy into a common.SignaturePolicyEnvelope, ready
+// for SetStateValidationParameter.
+func (s *StateEP) Policy() ([]byte, error) {
+	orgs := s.ListOrgs()
+	if len(orgs) == 0 {
+		return nil, fmt.Errorf("no organizations in policy")
+	}
+	n := s.required
+	if n == 0 {
+		n = len(orgs)
+	}
+	if n > len(orgs) {
+		return nil, fmt.Errorf("required %d out of %d organizations", n, len(orgs))
+	}
+
+	spe := &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule: &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_NOutOf_{
+				NOutOf: &common.SignaturePolicy_NOutOf{N: int32(n)},
+			},
+		},
+	}
+	nOutOf := spe.Rule.GetNOutOf()
+	for i, org := range orgs {
+		principal, err := proto.Marshal(&msp.MSPRole{MspIdentifier: org, Role: s.orgs[org]})
+		if err != nil {
+			return nil, err
+		}
+		spe.Identities = append(spe.Identities, &msp.MSPPrincipal{
+			PrincipalClassification: msp.MSPPrincipal_ROLE,
+			Principal:               principal,
+		})
+		nOutOf.Rules = append(nOutOf.Rules, &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_SignedBy{SignedBy: int32(i)},
+		})
+	}
+	return proto.Marshal(spe)
+}
@@ -0,0 +1,100 @@
+package statebased
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+func TestStateEPPolicy(t *testing.T) {
+	ep, err := NewStateEP(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.AddOrgs(RoleTypePeer, "Org2", "Org1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.AddOrgs(RoleTypeAdmin, "Org3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.SetRequired(2); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spe := &common.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(policy, spe); err != nil {
+		t.Fatal(err)
+	}
+	nOutOf := spe.GetRule().GetNOutOf()
+	if nOutOf.GetN() != 2 || len(nOutOf.GetRules()) != 3 {
+		t.Fatalf("got rule %v", spe.GetRule())
+	}
+	var got []*msp.MSPRole
+	for i, identity := range spe.Identities {
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(identity.Principal, role); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, role)
+		if nOutOf.Rules[i].GetSignedBy() != int32(i) {
+			t.Fatalf("rule %d signed by %d", i, nOutOf.Rules[i].GetSignedBy())
+		}
+	}
+	want := []*msp.MSPRole{
+		{MspIdentifier: "Org1", Role: msp.MSPRole_PEER},
+		{MspIdentifier: "Org2", Role: msp.MSPRole_PEER},
+		{MspIdentifier: "Org3", Role: msp.MSPRole_ADMIN},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if !proto.Equal(got[i], want[i]) {
+			t.Fatalf("identity %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	parsed, err := NewStateEP(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed.DelOrgs("Org3")
+	if orgs := parsed.ListOrgs(); !reflect.DeepEqual(orgs, []string{"Org1", "Org2"}) {
+		t.Fatalf("got orgs %q", orgs)
+	}
+	if parsed.required != 2 {
+		t.Fatalf("got required %d", parsed.required)
+	}
+}
+
+func TestStateEPInvalid(t *testing.T) {
+	ep, _ := NewStateEP(nil)
+	if _, err := ep.Policy(); err == nil {
+		t.Fatal("empty policy serialized")
+	}
+	if err := ep.AddOrgs(RoleType("CLIENT"), "Org1"); err == nil {
+		t.Fatal("unknown role accepted")
+	}
+	if err := ep.AddOrgs(RoleTypeMember, "Org1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.SetRequired(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ep.Policy(); err == nil {
+		t.Fatal("2 out of 1 serialized")
+	}
+	if err := ep.SetRequired(-1); err == nil {
+		t.Fatal("negative required count accepted")
+	}
+	if _, err := NewStateEP([]byte("garbage")); err == nil {
+		t.Fatal("garbage policy parsed")
+	}
+}
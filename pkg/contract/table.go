@@ -0,0 +1,221 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+)
+
+type KV struct {
+	Keys  []string
+	Value []byte
+}
+
+// AppName/Table<pk1,pk2,...>/pkv1:pkv2 -> value
+type Table struct {
+	app        string
+	table      string
+	fields     []string
+	collection string
+}
+
+func NewTable(app string, table string, fields ...string) *Table {
+	return &Table{app: app, table: table, fields: fields}
+}
+
+// NewPrivateTable creates a table whose rows are stored in the private data
+// collection instead of the world state.
+func NewPrivateTable(collection string, app string, table string, fields ...string) *Table {
+	return &Table{app: app, table: table, fields: fields, collection: collection}
+}
+
+func (t *Table) GetCollection() string {
+	return t.collection
+}
+
+func (t *Table) GetType() string {
+	return fmt.Sprintf("%s|%s<%s>", t.app, t.table, strings.Join(t.fields, ":"))
+}
+
+func (t *Table) createCompositeKey(stub IContractStub, keys []string) (string, error) {
+	if err := t.check(keys); err != nil {
+		return "", err
+	}
+	return stub.CreateCompositeKey(t.GetType(), keys)
+}
+
+// InsertOption customizes how Insert writes a row.
+type InsertOption func(o *insertOptions)
+
+type insertOptions struct {
+	policy []byte
+}
+
+// WithEndorsementPolicy attaches a key level endorsement policy to the
+// inserted row, see statebased.StateEP.
+func WithEndorsementPolicy(policy []byte) InsertOption {
+	return func(o *insertOptions) {
+		o.policy = policy
+	}
+}
+
+func (t *Table) Insert(stub IContractStub, keys []string, value []byte, opts ...InsertOption) error {
+	key, err := t.createCompositeKey(stub, keys)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		value = []byte{0x00}
+	}
+	err = t.putState(stub, key, value)
+	if err != nil {
+		return err
+	}
+
+	o := &insertOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.policy != nil {
+		return t.setValidationParameter(stub, key, o.policy)
+	}
+	return nil
+}
+
+func (t *Table) Delete(stub IContractStub, keys []string) error {
+	key, err := t.createCompositeKey(stub, keys)
+	if err != nil {
+		return err
+	}
+	return t.delState(stub, key)
+}
+
+func (t *Table) Update(stub IContractStub, keys []string, value []byte) error {
+	key, err := t.createCompositeKey(stub, keys)
+	if err != nil {
+		return err
+	}
+	return t.putState(stub, key, value)
+}
+
+func (t *Table) SplitKey(stub IContractStub, key string) ([]string, error) {
+	_, ks, err := stub.SplitCompositeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	err = t.check(ks)
+	if err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+func (t *Table) GetValue(stub IContractStub, keys []string) ([]byte, error) {
+	key, err := t.createCompositeKey(stub, keys)
+	if err != nil {
+		return nil, err
+	}
+	return t.getState(stub, key)
+}
+
+// Scan returns all rows whose keys start with leadingKeys, e.g. all orders
+// of one customer in a table keyed by ("customer", "id").
+func (t *Table) Scan(stub IContractStub, leadingKeys ...string) ([]*KV, error) {
+	if err := t.checkPrefix(leadingKeys); err != nil {
+		return nil, err
+	}
+	var (
+		iter IStateIterator
+		err  error
+	)
+	if t.collection != "" {
+		iter, err = stub.GetPrivateDataByPartialCompositeKey(t.collection, t.GetType(), leadingKeys)
+	} else {
+		iter, err = stub.GetStateByPartialCompositeKey(t.GetType(), leadingKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.collect(stub, iter)
+}
+
+// Page returns at most pageSize rows whose keys start with leadingKeys,
+// starting at bookmark. The returned metadata holds the fetched count and
+// the bookmark of the next page. Private tables cannot be paged.
+func (t *Table) Page(stub IContractStub, leadingKeys []string, pageSize int32, bookmark string) ([]*KV, *QueryMetadata, error) {
+	if t.collection != "" {
+		return nil, nil, fmt.Errorf("pagination is not supported on private data collection %s", t.collection)
+	}
+	if err := t.checkPrefix(leadingKeys); err != nil {
+		return nil, nil, err
+	}
+	iter, meta, err := stub.GetStateByPartialCompositeKeyWithPagination(t.GetType(), leadingKeys, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := t.collect(stub, iter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return list, meta, nil
+}
+
+func (t *Table) collect(stub IContractStub, iter IStateIterator) ([]*KV, error) {
+	defer iter.Close()
+
+	var list []*KV
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		keys, err := t.SplitKey(stub, kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &KV{Keys: keys, Value: kv.Value})
+	}
+	return list, nil
+}
+
+func (t *Table) getState(stub IContractStub, key string) ([]byte, error) {
+	if t.collection != "" {
+		return stub.GetPrivateData(t.collection, key)
+	}
+	return stub.GetState(key)
+}
+
+func (t *Table) putState(stub IContractStub, key string, value []byte) error {
+	if t.collection != "" {
+		return stub.PutPrivateData(t.collection, key, value)
+	}
+	return stub.PutState(key, value)
+}
+
+func (t *Table) delState(stub IContractStub, key string) error {
+	if t.collection != "" {
+		return stub.DelPrivateData(t.collection, key)
+	}
+	_, err := stub.DelState(key)
+	return err
+}
+
+func (t *Table) setValidationParameter(stub IContractStub, key string, ep []byte) error {
+	if t.collection != "" {
+		return stub.SetPrivateDataValidationParameter(t.collection, key, ep)
+	}
+	return stub.SetStateValidationParameter(key, ep)
+}
+
+func (t *Table) check(keys []string) error {
+	if len(keys) != len(t.fields) {
+		return fmt.Errorf("keys count not matched. got %d, need %d", len(keys), len(t.fields))
+	}
+	return nil
+}
+
+func (t *Table) checkPrefix(keys []string) error {
+	if len(keys) > len(t.fields) {
+		return fmt.Errorf("keys count out of range. got %d, max %d", len(keys), len(t.fields))
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package contract_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hello/pkg/contract"
+	"hello/pkg/contract/impl"
+	"hello/pkg/contract/statebased"
+)
+
+var testAddr = strings.Repeat("0a", 20)
+
+func insertRows(t *testing.T, chain *impl.MemoryFactoryChain, table *contract.Table, rows ...[]string) {
+	t.Helper()
+	stub := chain.NewStub(testAddr)
+	for _, keys := range rows {
+		if err := table.Insert(stub, keys, []byte(strings.Join(keys, "-"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func rowKeys(list []*contract.KV) [][]string {
+	var keys [][]string
+	for _, kv := range list {
+		keys = append(keys, kv.Keys)
+	}
+	return keys
+}
+
+func TestTableScan(t *testing.T) {
+	chain := impl.NewMemoryFactoryChain()
+	orders := contract.NewTable("app", "order", "customer", "id")
+	insertRows(t, chain, orders,
+		[]string{"c1", "2"}, []string{"c10", "1"}, []string{"c1", "1"}, []string{"c2", "1"})
+	stub := chain.NewStub(testAddr)
+
+	list, err := orders.Scan(stub, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"c1", "1"}, {"c1", "2"}}
+	if got := rowKeys(list); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if string(list[0].Value) != "c1-1" {
+		t.Fatalf("got value %q", list[0].Value)
+	}
+
+	list, err = orders.Scan(stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 4 {
+		t.Fatalf("got %d rows, want 4", len(list))
+	}
+
+	if _, err := orders.Scan(stub, "c1", "1", "x"); err == nil {
+		t.Fatal("scan with more keys than fields succeeded")
+	}
+}
+
+func TestTablePage(t *testing.T) {
+	chain := impl.NewMemoryFactoryChain()
+	orders := contract.NewTable("app", "order", "customer", "id")
+	insertRows(t, chain, orders,
+		[]string{"c1", "1"}, []string{"c1", "2"}, []string{"c1", "3"},
+		[]string{"c1", "4"}, []string{"c1", "5"}, []string{"c2", "1"})
+	stub := chain.NewStub(testAddr)
+
+	var (
+		pages    [][][]string
+		bookmark string
+	)
+	for {
+		list, meta, err := orders.Page(stub, []string{"c1"}, 2, bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(meta.FetchedRecordsCount) != len(list) {
+			t.Fatalf("fetched %d, got %d rows", meta.FetchedRecordsCount, len(list))
+		}
+		pages = append(pages, rowKeys(list))
+		if meta.Bookmark == "" {
+			break
+		}
+		bookmark = meta.Bookmark
+	}
+	want := [][][]string{
+		{{"c1", "1"}, {"c1", "2"}},
+		{{"c1", "3"}, {"c1", "4"}},
+		{{"c1", "5"}},
+	}
+	if !reflect.DeepEqual(pages, want) {
+		t.Fatalf("got %q, want %q", pages, want)
+	}
+
+	private := contract.NewPrivateTable("secret", "app", "order", "customer", "id")
+	if _, _, err := private.Page(stub, nil, 2, ""); err == nil {
+		t.Fatal("paging a private table succeeded")
+	}
+}
+
+func TestPrivateTable(t *testing.T) {
+	chain := impl.NewMemoryFactoryChain()
+	chain.DefineCollection("secret", "Org1")
+	prices := contract.NewPrivateTable("secret", "app", "price", "item")
+	stub := chain.NewStub(testAddr, impl.WithMSPID("Org1"))
+	if err := prices.Insert(stub, []string{"apple"}, []byte("3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := prices.GetValue(stub, []string{"apple"})
+	if err != nil || string(v) != "3" {
+		t.Fatalf("got %q, %v", v, err)
+	}
+	list, err := prices.Scan(stub)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("got %d rows, %v", len(list), err)
+	}
+	if v, _ := contract.NewTable("app", "price", "item").GetValue(stub, []string{"apple"}); v != nil {
+		t.Fatalf("private row found in the world state: %q", v)
+	}
+
+	outsider := chain.NewStub(testAddr, impl.WithMSPID("Org2"))
+	if _, err := prices.GetValue(outsider, []string{"apple"}); err == nil {
+		t.Fatal("non member read a private row")
+	}
+}
+
+func TestTableInsertWithEndorsementPolicy(t *testing.T) {
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypeMember, "Org1"); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := impl.NewMemoryFactoryChain()
+	assets := contract.NewTable("app", "asset", "id")
+	stub := chain.NewStub(testAddr)
+	if err := assets.Insert(stub, []string{"a1"}, []byte("x"), contract.WithEndorsementPolicy(policy)); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Commit(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := stub.CreateCompositeKey(assets.GetType(), []string{"a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := stub.GetStateValidationParameter(key)
+	if err != nil || !bytes.Equal(got, policy) {
+		t.Fatalf("got policy %x, %v", got, err)
+	}
+}
@@ -0,0 +1,432 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+type methodType struct {
+	method    reflect.Method
+	fn        reflect.Value // set for a function registered with RegisterFunc
+	argTypes  []reflect.Type
+	base      []int // base param index of each leading argument, see New
+	replyType reflect.Type
+	transient map[int]string             // param index -> transient field
+	names     []string                   // request param names, see ParamNames
+	defaults  map[string]json.RawMessage // param name -> default, see ParamDefault
+}
+
+type service struct {
+	name   string                 // name of service
+	rcvr   reflect.Value          // receiver of methods for the service, zero for functions
+	typ    reflect.Type           // type of the receiver
+	method map[string]*methodType // registered methods
+}
+
+// rpcImpl represents an RPC implement.
+type rpcImpl struct {
+	serviceMap sync.Map // map[string]*service
+	baseTypes  []reflect.Type
+}
+
+// New returns a new RPC. baseTypes are the types of the base params passed
+// to Handler, in that order. A method takes any of them, in any order, as
+// its leading arguments and the request params after them. Without base
+// types every method takes all base params positionally.
+func New(baseTypes ...reflect.Type) Rpc {
+	return &rpcImpl{baseTypes: baseTypes}
+}
+
+// baseArgs matches the leading arguments of a method to the base types.
+func (rpc *rpcImpl) baseArgs(argTypes []reflect.Type) []int {
+	var base []int
+	for _, argType := range argTypes {
+		idx := -1
+		for j, baseType := range rpc.baseTypes {
+			if argType == baseType {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			break
+		}
+		base = append(base, idx)
+	}
+	return base
+}
+
+// Register publishes in the server the set of methods of the
+// receiver value that satisfy the following conditions:
+//	- exported method of exported type
+//	- two arguments, both of exported type
+//	- the second argument is a pointer
+//	- one return value, of type error
+// It returns an error if the receiver is not an exported type or has
+// no suitable methods. It also logs the error using package log.
+// The client accesses each method using a string of the form "Type.Method",
+// where Type is the receiver's concrete type.
+func (rpc *rpcImpl) Register(rcvr interface{}) error {
+	return rpc.register(rcvr, "", false)
+}
+
+// RegisterName is like Register but uses the provided name for the type
+// instead of the receiver's concrete type.
+func (rpc *rpcImpl) RegisterName(name string, rcvr interface{}) error {
+	return rpc.register(rcvr, name, true)
+}
+
+// RegisterFunc publishes fn, e.g. a method value, as "Service.Method". The
+// function has the same signature as a method of Register without the
+// receiver.
+func (rpc *rpcImpl) RegisterFunc(serviceMethod string, fn interface{}) error {
+	dot := strings.LastIndex(serviceMethod, ".")
+	if dot <= 0 || dot == len(serviceMethod)-1 {
+		return errors.New("rpc.RegisterFunc: service/method ill-formed: " + serviceMethod)
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return fmt.Errorf("rpc.RegisterFunc: %s is not a function: %T", serviceMethod, fn)
+	}
+	mname := serviceMethod[dot+1:]
+	mtype, err := suitableFunc(v.Type(), 0, mname)
+	if err != nil {
+		return err
+	}
+	mtype.method = reflect.Method{Name: mname, Type: v.Type()}
+	mtype.fn = v
+	mtype.base = rpc.baseArgs(mtype.argTypes)
+
+	s := &service{name: serviceMethod[:dot], method: map[string]*methodType{mname: mtype}}
+	if _, dup := rpc.serviceMap.LoadOrStore(s.name, s); dup {
+		return errors.New("rpc: service already defined: " + s.name)
+	}
+	fmt.Printf("rpc.Register functon: %s\n", serviceMethod)
+	return nil
+}
+
+// Unregister removes the registered method "Service.Method". A service is
+// removed with its last method.
+func (rpc *rpcImpl) Unregister(serviceMethod string) error {
+	svc, _, err := rpc.readRequestServiceMethod(&Request{ServiceMethod: serviceMethod})
+	if err != nil {
+		return err
+	}
+	delete(svc.method, serviceMethod[strings.LastIndex(serviceMethod, ".")+1:])
+	if len(svc.method) == 0 {
+		rpc.serviceMap.Delete(svc.name)
+	}
+	return nil
+}
+
+// Configure applies opts to the registered method "Service.Method".
+func (rpc *rpcImpl) Configure(serviceMethod string, opts ...Option) error {
+	_, mtype, err := rpc.readRequestServiceMethod(&Request{ServiceMethod: serviceMethod})
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		if err := opt(mtype); err != nil {
+			return fmt.Errorf("rpc.Configure: %s: %v", serviceMethod, err)
+		}
+	}
+	if err := mtype.checkDefaults(); err != nil {
+		return fmt.Errorf("rpc.Configure: %s: %v", serviceMethod, err)
+	}
+	return nil
+}
+
+func (rpc *rpcImpl) Handler(req *Request, baseParam ...interface{}) (interface{}, error) {
+	service, mtype, args, err := rpc.readRequest(req, baseParam...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := service.call(mtype, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret.Interface(), nil
+}
+
+func (rpc *rpcImpl) register(rcvr interface{}, name string, useName bool) (err error) {
+	s := new(service)
+	s.typ = reflect.TypeOf(rcvr)
+	s.rcvr = reflect.ValueOf(rcvr)
+	sname := reflect.Indirect(s.rcvr).Type().Name()
+	if useName {
+		sname = name
+	}
+	if sname == "" {
+		return errors.New("rpc.Register: no service name for type " + s.typ.String())
+	}
+	if !isExported(sname) && !useName {
+		return errors.New("rpc.Register: type " + sname + " is not exported")
+	}
+	s.name = sname
+
+	// Install the methods
+	s.method, err = suitableMethods(s.typ)
+	if err != nil {
+		return err
+	}
+	for _, mtype := range s.method {
+		mtype.base = rpc.baseArgs(mtype.argTypes)
+	}
+
+	if len(s.method) == 0 {
+		str := ""
+
+		// To help the user, see if a pointer receiver would work.
+		method, err := suitableMethods(reflect.PtrTo(s.typ))
+		if len(method) != 0 {
+			str = "rpc.Register: type " + sname + " has no exported methods of suitable type (hint: pass a pointer to value of that type)"
+		} else {
+			str = "rpc.Register: type " + sname + " has no exported methods of suitable type"
+		}
+		return fmt.Errorf("%s, error: %v", str, err)
+	}
+
+	for name := range s.method {
+		fmt.Printf("rpc.Register functon: %s.%s\n", sname, name)
+	}
+
+	if _, dup := rpc.serviceMap.LoadOrStore(sname, s); dup {
+		return errors.New("rpc: service already defined: " + sname)
+	}
+	return nil
+}
+
+// suitableMethods returns suitable Rpc methods of typ, it will report
+// error using log if reportErr is true.
+func suitableArgs(mtype reflect.Type, first int, mname string) ([]reflect.Type, error) {
+	argTypes := make([]reflect.Type, 0, mtype.NumIn()-first)
+	for i := first; i < mtype.NumIn(); i++ {
+		argType := mtype.In(i)
+		if !isExportedOrBuiltinType(argType) {
+			return nil, fmt.Errorf("rpc.Register: argument type of method %q is not exported: %q\n", mname, argType)
+		}
+		argTypes = append(argTypes, argType)
+	}
+	return argTypes, nil
+}
+
+func suitableMethods(typ reflect.Type) (map[string]*methodType, error) {
+	methods := make(map[string]*methodType)
+	for m := 0; m < typ.NumMethod(); m++ {
+		method := typ.Method(m)
+		mtype := method.Type
+		mname := method.Name
+		// Method must be exported.
+		if method.PkgPath != "" {
+			continue
+		}
+
+		mt, err := suitableFunc(mtype, 1, mname)
+		if err != nil {
+			return nil, err
+		}
+		mt.method = method
+		methods[mname] = mt
+	}
+	return methods, nil
+}
+
+// suitableFunc checks the signature of a method or function whose arguments
+// start at index first.
+func suitableFunc(mtype reflect.Type, first int, mname string) (*methodType, error) {
+	argTypes, err := suitableArgs(mtype, first, mname)
+	if err != nil {
+		return nil, err
+	}
+
+	var replyType reflect.Type
+	if mtype.NumOut() > 0 {
+		replyType = mtype.Out(0)
+		if !isExportedOrBuiltinType(replyType) {
+			return nil, fmt.Errorf("rpc.Register: return type of method %q is not exported: %q\n", mname, replyType)
+		}
+	}
+
+	if mtype.NumOut() > 1 {
+		lastReplyType := mtype.Out(1)
+		if !isExportedOrBuiltinType(lastReplyType) {
+			return nil, fmt.Errorf("rpc.Register: return type of method %q is not exported: %q\n", mname, lastReplyType)
+		}
+		if !lastReplyType.Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+			return nil, fmt.Errorf("rpc.Register: return type of method %q last reply type not is error type\n", mname)
+		}
+	}
+
+	if mtype.NumOut() < 1 || mtype.NumOut() > 2 {
+		return nil, fmt.Errorf("rpc.Register: method %q has %d output parameters; needs exactly one or two\n", mname, mtype.NumOut())
+	}
+
+	return &methodType{argTypes: argTypes, replyType: replyType}, nil
+}
+
+// numIn returns the number of arguments including the receiver slot, which
+// a function does not have.
+func (m *methodType) numIn() int {
+	if m.fn.IsValid() {
+		return m.method.Type.NumIn() + 1
+	}
+	return m.method.Type.NumIn()
+}
+
+// numParams returns the number of request params, i.e. the arguments after
+// the base params. Without base types it counts every argument, as the base
+// params are only known by Handler.
+func (m *methodType) numParams() int {
+	return len(m.argTypes) - len(m.base)
+}
+
+func (s *service) call(mtype *methodType, args []reflect.Value) (replyv reflect.Value, err error) {
+	var returnValues []reflect.Value
+	if mtype.fn.IsValid() {
+		returnValues = mtype.fn.Call(args[1:])
+	} else {
+		// Invoke the method, providing a new value for the reply.
+		returnValues = mtype.method.Func.Call(args)
+	}
+	// The return value for the method is an error.
+	if len(returnValues) > 0 {
+		replyv = returnValues[0]
+	}
+	if len(returnValues) > 1 {
+		v := returnValues[1]
+		if !v.IsNil() {
+			err = v.Interface().(error)
+		}
+	}
+	return
+}
+
+func (rpc *rpcImpl) readRequest(req *Request, defaultParams ...interface{}) (service *service, mtype *methodType, argv []reflect.Value, err error) {
+	service, mtype, err = rpc.readRequestServiceMethod(req)
+	if err != nil {
+		return
+	}
+
+	base := mtype.base
+	if rpc.baseTypes == nil {
+		base = make([]int, len(defaultParams))
+		for i := range base {
+			base[i] = i
+		}
+	}
+	defaultParamsLen := len(base)
+
+	total := mtype.numIn() - defaultParamsLen - 1
+	params, err := mtype.resolveParams(req, defaultParamsLen, total)
+	if err != nil {
+		return
+	}
+
+	argv = make([]reflect.Value, len(mtype.argTypes)+1)
+	argv[0] = service.rcvr
+
+	for idx, j := range base {
+		if j >= len(defaultParams) {
+			err = fmt.Errorf("rpc: base param %d not provided", j)
+			return
+		}
+		argv[idx+1] = reflect.ValueOf(defaultParams[j])
+	}
+
+	next := 0
+	for i := 0; i < total; i++ {
+		targetType := mtype.argTypes[i+defaultParamsLen] //jump default_params
+		var arg reflect.Value
+		if field, ok := mtype.transient[i]; ok {
+			value, ok := req.Transient[field]
+			if !ok {
+				err = fmt.Errorf("rpc: transient field %q not found", field)
+				return
+			}
+			msg := json.RawMessage(value)
+			arg, err = convert(&msg, targetType)
+			if err != nil {
+				// the value is confidential, keep it out of the error
+				err = fmt.Errorf("rpc: convert transient field %q faild. expect %s, error: %v\n",
+					field, targetType, err)
+				return
+			}
+		} else {
+			arg, err = convert(params[next], targetType)
+			if err != nil {
+				err = fmt.Errorf("rpc: convert param faild. expect %s, found=%v, error: %v\n",
+					targetType, string(*params[next]), err)
+				return
+			}
+			next++
+		}
+		argv[i+defaultParamsLen+1] = arg
+	}
+
+	return
+}
+
+func (rpc *rpcImpl) readRequestServiceMethod(req *Request) (svc *service, mtype *methodType, err error) {
+	dot := strings.LastIndex(req.ServiceMethod, ".")
+	if dot < 0 {
+		err = errors.New("rpc: service/method request ill-formed: " + req.ServiceMethod)
+		return
+	}
+	serviceName := req.ServiceMethod[:dot]
+	methodName := req.ServiceMethod[dot+1:]
+
+	// Look up the request.
+	svci, ok := rpc.serviceMap.Load(serviceName)
+	if !ok {
+		err = errors.New("rpc: can't find service " + req.ServiceMethod)
+		return
+	}
+	svc = svci.(*service)
+	mtype = svc.method[methodName]
+	if mtype == nil {
+		err = errors.New("rpc: can't find method " + req.ServiceMethod)
+	}
+	return
+}
+
+func convert(msg *json.RawMessage, argType reflect.Type) (argv reflect.Value, err error) {
+	// Decode the argument value.
+	argIsValue := false // if true, need to indirect before calling.
+	if argType.Kind() == reflect.Ptr {
+		argv = reflect.New(argType.Elem())
+	} else {
+		argv = reflect.New(argType)
+		argIsValue = true
+	}
+	// argv guaranteed to be a pointer now.
+	if err = json.Unmarshal(*msg, argv.Interface()); err != nil {
+		return
+	}
+	if argIsValue {
+		argv = argv.Elem()
+	}
+	return
+}
+
+func isExported(name string) bool {
+	ch, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(ch)
+}
+
+// Is this type exported or a builtin?
+func isExportedOrBuiltinType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// PkgPath will be non-empty even for an exported type,
+	// so we need to check the type name as well.
+	return isExported(t.Name()) || t.PkgPath() == ""
+}
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Meta struct{ Name string }
+
+type Echo struct{}
+
+func (e *Echo) Both(m *Meta, c *CalcCtx, s string) (string, error) {
+	return m.Name + ":" + s, nil
+}
+
+func (e *Echo) Ctx(c *CalcCtx, s string) (string, error) {
+	return s, nil
+}
+
+func (e *Echo) Plain(s string) (string, error) {
+	return s, nil
+}
+
+// TestBaseParams checks that a method takes any base params, in any order,
+// before the request params.
+func TestBaseParams(t *testing.T) {
+	r := New(reflect.TypeOf(&CalcCtx{}), reflect.TypeOf(&Meta{}))
+	if err := r.Register(&Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	for method, want := range map[string]string{"Echo.Both": "m:x", "Echo.Ctx": "x", "Echo.Plain": "x"} {
+		ret, err := r.Handler(&Request{ServiceMethod: method, Params: rawParams(t, "x")}, &CalcCtx{}, &Meta{Name: "m"})
+		if err != nil || ret != want {
+			t.Errorf("%s: got %v, %v", method, ret, err)
+		}
+	}
+	for _, d := range r.Describe() {
+		if len(d.Params) != 1 {
+			t.Errorf("%s: described %d params", d.Name, len(d.Params))
+		}
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	r := newTestRpc(t)
+	if err := r.Unregister("Calc.Add"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Handler(&Request{ServiceMethod: "Calc.Add", Params: rawParams(t, 1, 2)}, &CalcCtx{})
+	if err == nil || !strings.Contains(err.Error(), "can't find") {
+		t.Fatalf("got %v", err)
+	}
+	if descs := r.Describe(); len(descs) != 0 {
+		t.Fatalf("described %d methods", len(descs))
+	}
+	if err := r.Unregister("Calc.Add"); err == nil {
+		t.Fatal("unregistered a missing method")
+	}
+	// the service name is free again
+	if err := r.Register(&Calc{}); err != nil {
+		t.Fatal(err)
+	}
+}
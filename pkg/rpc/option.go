@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Option configures a registered method, see Rpc.Configure.
+type Option func(m *methodType) error
+
+// Transient binds the request parameter at index idx (not counting the
+// base params such as the stub) to the field of the transient map. The
+// value is decoded as JSON like any other param but is neither sent in
+// the positional params nor written to the block.
+func Transient(idx int, field string) Option {
+	return func(m *methodType) error {
+		if idx < 0 || idx >= m.numParams() {
+			return fmt.Errorf("transient param index %d out of range", idx)
+		}
+		if m.transient == nil {
+			m.transient = map[int]string{}
+		}
+		m.transient[idx] = field
+		return nil
+	}
+}
+
+// ParamNames names all request params (not counting the base params, but
+// the transient ones) in order, so that a request can send them as a JSON
+// object keyed by name instead of an array. A named pointer param may be
+// omitted and is nil. A method whose only request param is a struct takes
+// the object as that struct without names.
+func ParamNames(names ...string) Option {
+	return func(m *methodType) error {
+		if len(names) != m.numParams() {
+			return fmt.Errorf("%d param names for %d params", len(names), m.numParams())
+		}
+		seen := map[string]bool{}
+		for _, name := range names {
+			if name == "" || seen[name] {
+				return fmt.Errorf("invalid or duplicate param name %q", name)
+			}
+			seen[name] = true
+		}
+		m.names = names
+		return nil
+	}
+}
+
+// ParamDefault makes the named param optional: a request which omits it,
+// by name or as a trailing positional param, gets value. The param must be
+// named with ParamNames, except for a method taking a single struct, where
+// name is a JSON field of the struct, sent by name or in the positional
+// object. Configure fails unless value converts to the type of the param or
+// field.
+func ParamDefault(name string, value interface{}) Option {
+	return func(m *methodType) error {
+		buf, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("default of param %q: %v", name, err)
+		}
+		if m.defaults == nil {
+			m.defaults = map[string]json.RawMessage{}
+		}
+		m.defaults[name] = buf
+		return nil
+	}
+}
+
+// checkDefaults checks the defaults once all options are applied, so that
+// ParamDefault may come before ParamNames.
+func (m *methodType) checkDefaults() error {
+	base := len(m.base)
+	for name, d := range m.defaults {
+		t, ok := m.paramType(name, base)
+		if !ok {
+			if _, isStruct := m.structParam(base); !isStruct && len(m.names) == 0 {
+				return fmt.Errorf("default of param %q without ParamNames", name)
+			}
+			return fmt.Errorf("default of unknown param %q", name)
+		}
+		d := d
+		if _, err := convert(&d, t); err != nil {
+			return fmt.Errorf("default of param %q does not convert to %s: %v", name, t, err)
+		}
+	}
+	return nil
+}
+
+// paramType returns the type of the named request param, or of the field of
+// the struct param.
+func (m *methodType) paramType(name string, base int) (reflect.Type, bool) {
+	for i, n := range m.names {
+		if n == name {
+			return m.argTypes[base+i], true
+		}
+	}
+	if idx, ok := m.structParam(base); ok {
+		t, ok := jsonFields(m.argTypes[base+idx])[name]
+		return t, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,221 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type CalcCtx struct{}
+
+type Calc struct{}
+
+func (c *Calc) Add(b *CalcCtx, x int, y int) (int, error) {
+	return x + y, nil
+}
+
+func newTestRpc(t *testing.T) Rpc {
+	t.Helper()
+	r := New(reflect.TypeOf(&CalcCtx{}))
+	if err := r.Register(&Calc{}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func rawParams(t *testing.T, params ...interface{}) []*json.RawMessage {
+	t.Helper()
+	var raws []*json.RawMessage
+	for _, p := range params {
+		buf, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := json.RawMessage(buf)
+		raws = append(raws, &msg)
+	}
+	return raws
+}
+
+func TestTransient(t *testing.T) {
+	r := newTestRpc(t)
+	if err := r.Configure("Calc.Add", Transient(1, "y")); err != nil {
+		t.Fatal(err)
+	}
+
+	ret, err := r.Handler(&Request{
+		ServiceMethod: "Calc.Add",
+		Params:        rawParams(t, 1),
+		Transient:     map[string][]byte{"y": []byte("41")},
+	}, &CalcCtx{})
+	if err != nil || ret != 42 {
+		t.Fatalf("got %v, %v", ret, err)
+	}
+
+	_, err = r.Handler(&Request{ServiceMethod: "Calc.Add", Params: rawParams(t, 1)}, &CalcCtx{})
+	if err == nil || !strings.Contains(err.Error(), `"y" not found`) {
+		t.Fatalf("missing transient field: got %v", err)
+	}
+
+	_, err = r.Handler(&Request{
+		ServiceMethod: "Calc.Add",
+		Params:        rawParams(t, 1),
+		Transient:     map[string][]byte{"y": []byte(`"secret"`)},
+	}, &CalcCtx{})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("bad transient value: got %v", err)
+	}
+}
+
+func TestTransientOutOfRange(t *testing.T) {
+	r := newTestRpc(t)
+	for _, idx := range []int{-1, 2} {
+		if err := r.Configure("Calc.Add", Transient(idx, "y")); err == nil {
+			t.Errorf("transient index %d accepted", idx)
+		}
+	}
+}
+
+type Order struct {
+	Item string `json:"item"`
+	Qty  int    `json:"qty"`
+	Note string `json:"note,omitempty"`
+}
+
+type Form struct{}
+
+func (f *Form) Submit(b *CalcCtx, o Order) (string, error) {
+	return fmt.Sprintf("%s x%d", o.Item, o.Qty), nil
+}
+
+func newFormRpc(t *testing.T) Rpc {
+	t.Helper()
+	r := New(reflect.TypeOf(&CalcCtx{}))
+	if err := r.Register(&Form{}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func namedParams(t *testing.T, params map[string]interface{}) map[string]*json.RawMessage {
+	t.Helper()
+	named := map[string]*json.RawMessage{}
+	for name, p := range params {
+		named[name] = rawParams(t, p)[0]
+	}
+	return named
+}
+
+func TestParamNamesCount(t *testing.T) {
+	for _, names := range [][]string{{"x"}, {"x", "y", "z"}, {"x", "x"}, {"x", ""}} {
+		if err := newTestRpc(t).Configure("Calc.Add", ParamNames(names...)); err == nil {
+			t.Errorf("names %v accepted", names)
+		}
+	}
+
+	// a transient param has a name too
+	r := newTestRpc(t)
+	if err := r.Configure("Calc.Add", Transient(1, "secret"), ParamNames("x")); err == nil {
+		t.Fatal("names without the transient param accepted")
+	}
+	r = newTestRpc(t)
+	if err := r.Configure("Calc.Add", Transient(1, "secret"), ParamNames("x", "y")); err != nil {
+		t.Fatal(err)
+	}
+	ret, err := r.Handler(&Request{
+		ServiceMethod: "Calc.Add",
+		NamedParams:   namedParams(t, map[string]interface{}{"x": 1}),
+		Transient:     map[string][]byte{"secret": []byte("2")},
+	}, &CalcCtx{})
+	if err != nil || ret != 3 {
+		t.Fatalf("got %v, %v", ret, err)
+	}
+}
+
+func TestParamDefault(t *testing.T) {
+	r := newTestRpc(t)
+	// the default may come before the names
+	if err := r.Configure("Calc.Add", ParamDefault("y", 2), ParamNames("x", "y")); err != nil {
+		t.Fatal(err)
+	}
+	ret, err := r.Handler(&Request{ServiceMethod: "Calc.Add", NamedParams: namedParams(t, map[string]interface{}{"x": 1})}, &CalcCtx{})
+	if err != nil || ret != 3 {
+		t.Fatalf("named: got %v, %v", ret, err)
+	}
+	ret, err = r.Handler(&Request{ServiceMethod: "Calc.Add", Params: rawParams(t, 1)}, &CalcCtx{})
+	if err != nil || ret != 3 {
+		t.Fatalf("positional: got %v, %v", ret, err)
+	}
+}
+
+func TestParamDefaultInvalid(t *testing.T) {
+	cases := []struct {
+		rpc    func(t *testing.T) Rpc
+		method string
+		opts   []Option
+		want   string
+	}{
+		{newTestRpc, "Calc.Add", []Option{ParamNames("x", "y"), ParamDefault("z", 1)}, `unknown param "z"`},
+		{newTestRpc, "Calc.Add", []Option{ParamDefault("y", 1)}, `param "y" without ParamNames`},
+		{newTestRpc, "Calc.Add", []Option{ParamNames("x", "y"), ParamDefault("y", "two")}, `param "y" does not convert`},
+		{newFormRpc, "Form.Submit", []Option{ParamDefault("color", "red")}, `unknown param "color"`},
+		{newFormRpc, "Form.Submit", []Option{ParamDefault("qty", "many")}, `param "qty" does not convert`},
+	}
+	for _, c := range cases {
+		err := c.rpc(t).Configure(c.method, c.opts...)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: got %v, want %s", c.method, err, c.want)
+		}
+	}
+}
+
+func TestStructParam(t *testing.T) {
+	r := newFormRpc(t)
+	if err := r.Configure("Form.Submit", ParamDefault("qty", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	ret, err := r.Handler(&Request{ServiceMethod: "Form.Submit", NamedParams: namedParams(t, map[string]interface{}{"item": "tea"})}, &CalcCtx{})
+	if err != nil || ret != "tea x1" {
+		t.Fatalf("got %v, %v", ret, err)
+	}
+	_, err = r.Handler(&Request{
+		ServiceMethod: "Form.Submit",
+		NamedParams:   namedParams(t, map[string]interface{}{"item": "tea", "colour": "red"}),
+	}, &CalcCtx{})
+	if err == nil || !strings.Contains(err.Error(), `unknown param "colour"`) {
+		t.Fatalf("unknown key: got %v", err)
+	}
+
+	// the positional object takes the defaults too
+	ret, err = r.Handler(&Request{ServiceMethod: "Form.Submit", Params: rawParams(t, map[string]string{"item": "tea"})}, &CalcCtx{})
+	if err != nil || ret != "tea x1" {
+		t.Fatalf("positional: got %v, %v", ret, err)
+	}
+	ret, err = r.Handler(&Request{ServiceMethod: "Form.Submit", Params: rawParams(t, map[string]interface{}{"item": "tea", "qty": 3})}, &CalcCtx{})
+	if err != nil || ret != "tea x3" {
+		t.Fatalf("positional with qty: got %v, %v", ret, err)
+	}
+	ret, err = r.Handler(&Request{ServiceMethod: "Form.Submit", Params: rawParams(t)}, &CalcCtx{})
+	if err != nil || ret != " x1" {
+		t.Fatalf("omitted: got %v, %v", ret, err)
+	}
+}
+
+func TestDescribeStructDefaults(t *testing.T) {
+	r := newFormRpc(t)
+	if err := r.Configure("Form.Submit", ParamDefault("qty", 1)); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(r.Describe()[0].Params[0].Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{"item":{"type":"string"},"note":{"type":"string"},"qty":{"default":1,"type":"integer"}},` +
+		`"required":["item"],"type":"object"}`
+	if string(buf) != want {
+		t.Fatalf("got %s", buf)
+	}
+}
@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var nullParam = json.RawMessage("null")
+
+// resolveParams returns the params sent in the request for the request
+// params not bound to the transient map, in order. base is the number of
+// base params and total the number of request params of the method.
+func (m *methodType) resolveParams(req *Request, base, total int) ([]*json.RawMessage, error) {
+	var idxs []int
+	for i := 0; i < total; i++ {
+		if _, ok := m.transient[i]; !ok {
+			idxs = append(idxs, i)
+		}
+	}
+	if req.NamedParams != nil {
+		return m.namedParams(req.NamedParams, base, idxs)
+	}
+
+	params := append([]*json.RawMessage{}, req.Params...)
+	if _, ok := m.structParam(base); ok && len(m.defaults) > 0 && len(params) <= 1 {
+		// the object of the struct param takes the defaults of its fields
+		obj := map[string]*json.RawMessage{}
+		if len(params) == 0 || params[0] != nil && json.Unmarshal(*params[0], &obj) == nil && obj != nil {
+			v, err := m.structDefaults(obj)
+			if err != nil {
+				return nil, err
+			}
+			return []*json.RawMessage{v}, nil
+		}
+	}
+	if len(params) < len(idxs) {
+		// omitted trailing params take their defaults
+		for _, i := range idxs[len(params):] {
+			d, ok := m.optional(i, base)
+			if !ok {
+				break
+			}
+			params = append(params, d)
+		}
+	}
+	if len(params) != len(idxs) {
+		return nil, fmt.Errorf("rpc: params not matched. got %d, need %d", len(req.Params), len(idxs))
+	}
+	return params, nil
+}
+
+func (m *methodType) namedParams(named map[string]*json.RawMessage, base int, idxs []int) ([]*json.RawMessage, error) {
+	if len(m.names) == 0 {
+		if idx, ok := m.structParam(base); ok {
+			// the object is the single struct argument
+			fields := jsonFields(m.argTypes[base+idx])
+			for name := range named {
+				if _, ok := fields[name]; !ok {
+					return nil, fmt.Errorf("rpc: unknown param %q", name)
+				}
+			}
+			v, err := m.structDefaults(named)
+			if err != nil {
+				return nil, err
+			}
+			return []*json.RawMessage{v}, nil
+		}
+		return nil, errors.New("rpc: method takes positional params only")
+	}
+
+	known := map[string]bool{}
+	params := make([]*json.RawMessage, 0, len(idxs))
+	for _, i := range idxs {
+		if i >= len(m.names) {
+			return nil, fmt.Errorf("rpc: param %d has no name", i)
+		}
+		name := m.names[i]
+		known[name] = true
+		v, ok := named[name]
+		if !ok {
+			if v, ok = m.optional(i, base); !ok {
+				return nil, fmt.Errorf("rpc: param %q missing", name)
+			}
+		}
+		if v == nil {
+			null := nullParam
+			v = &null
+		}
+		params = append(params, v)
+	}
+	for name := range named {
+		if !known[name] {
+			return nil, fmt.Errorf("rpc: unknown param %q", name)
+		}
+	}
+	return params, nil
+}
+
+// structDefaults returns the object of the struct param with the defaults
+// of the fields it omits.
+func (m *methodType) structDefaults(obj map[string]*json.RawMessage) (*json.RawMessage, error) {
+	merged := map[string]*json.RawMessage{}
+	for name, d := range m.defaults {
+		d := d
+		merged[name] = &d
+	}
+	for name, v := range obj {
+		merged[name] = v
+	}
+	buf, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	msg := json.RawMessage(buf)
+	return &msg, nil
+}
+
+// optional returns the value of the named request param at index i when
+// the request omits it: its default, or null for a pointer.
+func (m *methodType) optional(i, base int) (*json.RawMessage, bool) {
+	if i >= len(m.names) {
+		return nil, false
+	}
+	if d, ok := m.defaults[m.names[i]]; ok {
+		return &d, true
+	}
+	if m.argTypes[base+i].Kind() == reflect.Ptr {
+		null := nullParam
+		return &null, true
+	}
+	return nil, false
+}
+
+// structParam returns the index of the request param which takes the JSON
+// object of a request for a method without param names: its only request
+// param not bound to the transient map, if that is a struct.
+func (m *methodType) structParam(base int) (int, bool) {
+	if len(m.names) > 0 {
+		return 0, false
+	}
+	idx, n := 0, 0
+	for i := 0; i < len(m.argTypes)-base; i++ {
+		if _, ok := m.transient[i]; !ok {
+			idx = i
+			n++
+		}
+	}
+	return idx, n == 1 && isStruct(m.argTypes[base+idx])
+}
+
+// jsonFields returns the types of the JSON fields of the struct t.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := map[string]reflect.Type{}
+	eachJSONField(t, func(name string, field reflect.StructField, omitempty bool) {
+		fields[name] = field.Type
+	})
+	return fields
+}
+
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
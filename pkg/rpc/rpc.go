@@ -5,12 +5,21 @@ import "encoding/json"
 type Rpc interface {
 	Register(rcvr interface{}) error
 	RegisterName(name string, rcvr interface{}) error
+	RegisterFunc(serviceMethod string, fn interface{}) error
+	Unregister(serviceMethod string) error
+	Configure(serviceMethod string, opts ...Option) error
+	Describe() []*MethodDesc
 	Handler(req *Request, baseParam ...interface{}) (interface{}, error)
 }
 
 type Request struct {
 	ServiceMethod string             `json:"func_name"` // format: "Service.Method"
 	Params        []*json.RawMessage `json:"params"`
+	Transient     map[string][]byte  `json:"-"` // never serialized, see Transient option
+
+	// NamedParams replaces Params when the params are sent as an object
+	// keyed by name, see ParamNames.
+	NamedParams map[string]*json.RawMessage `json:"-"`
 }
 
 type ClientRequest struct {
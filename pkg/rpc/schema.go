@@ -0,0 +1,194 @@
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MethodDesc describes a registered method, see Rpc.Describe.
+type MethodDesc struct {
+	Name    string                 `json:"name"`
+	Params  []*ParamDesc           `json:"params"`
+	Returns map[string]interface{} `json:"returns,omitempty"` // JSON Schema, empty if only an error is returned
+}
+
+// ParamDesc describes a request param by its index and JSON Schema.
+type ParamDesc struct {
+	Index     int                    `json:"index"`
+	Name      string                 `json:"name,omitempty"` // see ParamNames
+	Schema    map[string]interface{} `json:"schema"`
+	Default   json.RawMessage        `json:"default,omitempty"`   // see ParamDefault
+	Transient string                 `json:"transient,omitempty"` // field of the transient map, see Transient
+}
+
+var (
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	timeType      = reflect.TypeOf(time.Time{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Describe returns the registered methods sorted by name. Arguments taking
+// base params, e.g. the stub, are not request params.
+func (rpc *rpcImpl) Describe() []*MethodDesc {
+	var descs []*MethodDesc
+	rpc.serviceMap.Range(func(key, value interface{}) bool {
+		svc := value.(*service)
+		for name, mtype := range svc.method {
+			descs = append(descs, mtype.describe(svc.name+"."+name, len(mtype.base)))
+		}
+		return true
+	})
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Name < descs[j].Name
+	})
+	return descs
+}
+
+func (m *methodType) describe(name string, baseParams int) *MethodDesc {
+	desc := &MethodDesc{Name: name, Params: []*ParamDesc{}}
+	for i := baseParams; i < len(m.argTypes); i++ {
+		idx := i - baseParams
+		p := &ParamDesc{
+			Index:     idx,
+			Schema:    jsonSchema(m.argTypes[i], map[reflect.Type]bool{}),
+			Transient: m.transient[idx],
+		}
+		if idx < len(m.names) {
+			p.Name = m.names[idx]
+			p.Default = m.defaults[p.Name]
+		}
+		desc.Params = append(desc.Params, p)
+	}
+	if idx, ok := m.structParam(baseParams); ok {
+		describeFieldDefaults(desc.Params[idx].Schema, m.defaults)
+	}
+	if m.replyType != nil && !(m.method.Type.NumOut() == 1 && m.replyType.Implements(errorType)) {
+		desc.Returns = jsonSchema(m.replyType, map[reflect.Type]bool{})
+	}
+	return desc
+}
+
+// describeFieldDefaults sets the defaults of the fields of a struct param
+// in its schema. A field with a default is not required.
+func describeFieldDefaults(schema map[string]interface{}, defaults map[string]json.RawMessage) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, d := range defaults {
+		if field, ok := properties[name].(map[string]interface{}); ok {
+			field["default"] = d
+		}
+	}
+	if required, ok := schema["required"].([]string); ok {
+		var left []string
+		for _, name := range required {
+			if _, ok := defaults[name]; !ok {
+				left = append(left, name)
+			}
+		}
+		if len(left) > 0 {
+			schema["required"] = left
+		} else {
+			delete(schema, "required")
+		}
+	}
+}
+
+// jsonSchema renders the JSON encoding of t as JSON Schema. Types with a
+// custom JSON encoding are described by an empty schema.
+func jsonSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawType, t.Implements(marshalerType), reflect.PtrTo(t).Implements(marshalerType):
+		return map[string]interface{}{}
+	case t.Implements(textType), reflect.PtrTo(t).Implements(textType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive type
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		structFields(t, visiting, properties, &required)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	// interface{} and others
+	return map[string]interface{}{}
+}
+
+func structFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	eachJSONField(t, func(name string, field reflect.StructField, omitempty bool) {
+		properties[name] = jsonSchema(field.Type, visiting)
+		if !omitempty {
+			*required = append(*required, name)
+		}
+	})
+}
+
+// eachJSONField calls f for the fields of the struct t encoded to JSON, the
+// ones of embedded structs included, with their JSON names.
+func eachJSONField(t reflect.Type, f func(name string, field reflect.StructField, omitempty bool)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// fields of an embedded struct are promoted
+			eachJSONField(ft, f)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		f(name, field, strings.Contains(opts, "omitempty"))
+	}
+}
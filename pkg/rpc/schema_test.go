@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Node struct {
+	Name     string            `json:"name"`
+	Children []*Node           `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type Doc struct {
+	Node
+	Body    []byte    `json:"body"`
+	Created time.Time `json:"created"`
+	Raw     json.RawMessage
+	Score   *float64 `json:"score,omitempty"`
+	Secret  string   `json:"-"`
+	hidden  bool
+}
+
+func schemaJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	buf, err := json.Marshal(jsonSchema(reflect.TypeOf(v), map[reflect.Type]bool{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
+
+func TestJSONSchema(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{true, `{"type":"boolean"}`},
+		{uint8(1), `{"type":"integer"}`},
+		{1.5, `{"type":"number"}`},
+		{[]byte{}, `{"contentEncoding":"base64","type":"string"}`},
+		{[2]string{}, `{"items":{"type":"string"},"type":"array"}`},
+		{time.Time{}, `{"format":"date-time","type":"string"}`},
+		{Node{}, `{"properties":{"children":{"items":{"type":"object"},"type":"array"},` +
+			`"labels":{"additionalProperties":{"type":"string"},"type":"object"},"name":{"type":"string"}},` +
+			`"required":["name"],"type":"object"}`},
+		{&Doc{}, `{"properties":{"Raw":{},"body":{"contentEncoding":"base64","type":"string"},` +
+			`"children":{"items":{"properties":{"children":{"items":{"type":"object"},"type":"array"},` +
+			`"labels":{"additionalProperties":{"type":"string"},"type":"object"},"name":{"type":"string"}},` +
+			`"required":["name"],"type":"object"},"type":"array"},` +
+			`"created":{"format":"date-time","type":"string"},` +
+			`"labels":{"additionalProperties":{"type":"string"},"type":"object"},` +
+			`"name":{"type":"string"},"score":{"type":"number"}},` +
+			`"required":["name","body","created","Raw"],"type":"object"}`},
+	}
+	for _, c := range cases {
+		if got := schemaJSON(t, c.v); got != c.want {
+			t.Errorf("%T:\n got %s\nwant %s", c.v, got, c.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	r := newTestRpc(t)
+	if err := r.Configure("Calc.Add", Transient(1, "y")); err != nil {
+		t.Fatal(err)
+	}
+
+	descs := r.Describe()
+	if len(descs) != 1 || descs[0].Name != "Calc.Add" {
+		t.Fatalf("descs = %v", descs)
+	}
+	buf, err := json.Marshal(descs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"Calc.Add","params":[{"index":0,"schema":{"type":"integer"}},` +
+		`{"index":1,"schema":{"type":"integer"},"transient":"y"}],"returns":{"type":"integer"}}`
+	if string(buf) != want {
+		t.Fatalf("got %s", buf)
+	}
+}